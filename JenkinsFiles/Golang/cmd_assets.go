@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/changeset"
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/cocos"
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/config"
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/xcassets"
+)
+
+var assetsSyncCmd = &command{
+	group:   "assets",
+	name:    "sync",
+	summary: "Copy the configured asset catalog sets from Unity to Cocos",
+}
+
+var assetsValidateCmd = &command{
+	group:   "assets",
+	name:    "validate",
+	summary: "Check asset catalog sets against their Contents.json",
+}
+
+func init() {
+	assetsSyncCmd.run = runAssetsSync
+	assetsValidateCmd.run = runAssetsValidate
+	register(assetsSyncCmd)
+	register(assetsValidateCmd)
+}
+
+// defaultAssetSets are synced when no asset sets are configured.
+const defaultAssetSets = "AppIcon.appiconset"
+
+// unityAssetCatalog is the Images.xcassets of the Unity iOS export.
+func unityAssetCatalog(cfg *config.Config) string {
+	return filepath.Join(cfg.UnityProject, "Unity-iPhone", "Images.xcassets")
+}
+
+// assetSetNames splits the configured asset sets.
+func assetSetNames(cfg *config.Config) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(cfg.AssetSets, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if filepath.Ext(name) == "" {
+			return nil, fmt.Errorf("asset set %q needs its folder extension, e.g. %s.imageset", name, name)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func runAssetsSync(args []string) error {
+	fs := newFlagSet(assetsSyncCmd, "")
+	flags := bindPlanFlags(fs)
+	mode := bindIconsModeFlag(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := checkIconsMode(*mode); err != nil {
+		return err
+	}
+	cfg, plan, err := flags.open(assetsSyncCmd, config.Config{
+		BaseDir:      executableDir(),
+		CocosProject: "cocosProject",
+		UnityProject: "UnityBuild",
+		AssetSets:    defaultAssetSets,
+	})
+	if err != nil {
+		return err
+	}
+	defer plan.Close()
+	defer plan.WriteText(os.Stdout)
+	project, err := openCocosProject(cfg)
+	if err != nil {
+		return err
+	}
+	return syncAssets(plan, cfg, project, *mode)
+}
+
+// syncAssets brings every configured set of the Unity asset catalog into
+// the Cocos one. App icon sets go through syncIcons; other sets replace
+// the Cocos set of the same name once they validate.
+func syncAssets(plan *changeset.Plan, cfg *config.Config, project *cocos.Project, iconsMode string) error {
+	names, err := assetSetNames(cfg)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		src := filepath.Join(unityAssetCatalog(cfg), name)
+		dst := filepath.Join(project.AssetCatalog(), name)
+		if filepath.Ext(name) == xcassets.AppIconSetExt {
+			err = syncIcons(plan, src, dst, iconsMode)
+		} else {
+			err = copyAssetSet(plan, src, dst)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyAssetSet replaces the set dst with src.
+func copyAssetSet(plan *changeset.Plan, src, dst string) error {
+	name := filepath.Base(src)
+	if info, err := os.Stat(src); err != nil || !info.IsDir() {
+		return fmt.Errorf("Unity %s not found at %s", name, src)
+	}
+	if errors, _, err := reportAssetSet(src); err != nil {
+		return err
+	} else if errors > 0 {
+		return fmt.Errorf("Unity %s has %d error(s)", name, errors)
+	}
+	if err := plan.RemoveAll(dst); err != nil {
+		return fmt.Errorf("failed to remove Cocos %s: %w", name, err)
+	}
+	if err := plan.CopyDir(src, dst); err != nil {
+		return fmt.Errorf("failed to copy %s: %w", name, err)
+	}
+	fmt.Printf("✅ Unity %s replaced the Cocos one.\n", name)
+	return nil
+}
+
+func runAssetsValidate(args []string) error {
+	fs := newFlagSet(assetsValidateCmd, "[Set.imageset|Set.colorset|...]")
+	strict := fs.Bool("strict", false, "treat warnings (empty slots, unlisted files) as errors")
+	cfgFlags := config.Bind(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	sets := fs.Args()
+	if len(sets) == 0 {
+		// Both ends of assets sync; Cocos sets are only there after a sync.
+		cfg, err := cfgFlags.Resolve(config.Config{
+			BaseDir:      executableDir(),
+			CocosProject: "cocosProject",
+			UnityProject: "UnityBuild",
+			AssetSets:    defaultAssetSets,
+		})
+		if err != nil {
+			return err
+		}
+		names, err := assetSetNames(cfg)
+		if err != nil {
+			return err
+		}
+		project, err := openCocosProject(cfg)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			sets = append(sets, filepath.Join(unityAssetCatalog(cfg), name))
+			if cocosSet := filepath.Join(project.AssetCatalog(), name); dirExists(cocosSet) {
+				sets = append(sets, cocosSet)
+			}
+		}
+	}
+	return validateAssetSets(sets, *strict)
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// reportAssetSet validates the set dir and prints its issues.
+func reportAssetSet(dir string) (errors, warnings int, err error) {
+	issues, err := xcassets.ValidateSet(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, issue := range issues {
+		if issue.Severity == xcassets.Error {
+			errors++
+			fmt.Println("❌", issue)
+		} else {
+			warnings++
+			fmt.Println("⚠️", issue)
+		}
+	}
+	return errors, warnings, nil
+}
+
+// validateAssetSets reports on every set and returns the exit code the
+// findings call for, like xcode validate.
+func validateAssetSets(sets []string, strict bool) error {
+	code := exitOK
+	for _, dir := range sets {
+		errors, warnings, err := reportAssetSet(dir)
+		if err != nil {
+			fmt.Printf("❌ Failed to read %s: %v\n", dir, err)
+			code = exitFailed
+			continue
+		}
+		switch {
+		case errors > 0 || (strict && warnings > 0):
+			fmt.Printf("❌ %s: %d error(s), %d warning(s)\n", dir, errors, warnings)
+			if code != exitFailed {
+				code = exitFindings
+			}
+		case warnings > 0:
+			fmt.Printf("⚠️ %s: %d warning(s)\n", dir, warnings)
+			if code == exitOK {
+				code = exitWarnings
+			}
+		default:
+			fmt.Printf("✅ %s: no issues found\n", dir)
+		}
+	}
+	if code != exitOK {
+		return exitCode(code)
+	}
+	return nil
+}
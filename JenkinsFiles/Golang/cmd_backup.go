@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/changeset"
+)
+
+var backupListCmd = &command{
+	group:   "backup",
+	name:    "list",
+	summary: "List the backup journals of earlier runs",
+}
+
+var backupRollbackCmd = &command{
+	group:   "backup",
+	name:    "rollback",
+	summary: "Restore the files changed by a run; by default the newest run not yet rolled back",
+}
+
+func init() {
+	backupListCmd.run = runBackupList
+	backupRollbackCmd.run = runBackupRollback
+	register(backupListCmd)
+	register(backupRollbackCmd)
+}
+
+func runBackupList(args []string) error {
+	fs := newFlagSet(backupListCmd, "")
+	root := fs.String("dir", changeset.JournalRoot(), "folder holding the backup journals")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usageErrorf("unexpected arguments: %v", fs.Args())
+	}
+
+	dirs, err := changeset.Journals(*root)
+	if err != nil {
+		return fmt.Errorf("failed to read journals: %w", err)
+	}
+	if len(dirs) == 0 {
+		fmt.Println("ℹ️ No journals in", *root)
+	}
+	for _, dir := range dirs {
+		j, err := changeset.LoadJournal(dir)
+		if err != nil {
+			fmt.Println("⚠️", err)
+			continue
+		}
+		state := ""
+		if j.RolledBack {
+			state = " (rolled back)"
+		}
+		fmt.Printf("%s  %s  %s  %d path(s)%s\n", dir, j.Started.Format("2006-01-02 15:04:05"), j.Tool, len(j.Entries), state)
+	}
+	return nil
+}
+
+func runBackupRollback(args []string) error {
+	fs := newFlagSet(backupRollbackCmd, "[journal-dir]")
+	root := fs.String("dir", changeset.JournalRoot(), "folder holding the backup journals")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return usageErrorf("at most one journal can be rolled back")
+	}
+
+	var j *changeset.Journal
+	if dir := fs.Arg(0); dir != "" {
+		var err error
+		if j, err = changeset.LoadJournal(dir); err != nil {
+			return fmt.Errorf("failed to load journal: %w", err)
+		}
+	} else {
+		dirs, err := changeset.Journals(*root)
+		if err != nil {
+			return fmt.Errorf("failed to read journals: %w", err)
+		}
+		// Walk back from the newest run, so repeated calls undo one run each.
+		for i := len(dirs) - 1; i >= 0 && j == nil; i-- {
+			candidate, err := changeset.LoadJournal(dirs[i])
+			if err == nil && !candidate.RolledBack {
+				j = candidate
+			}
+		}
+		if j == nil {
+			return fmt.Errorf("no journal left to roll back in %s", *root)
+		}
+	}
+
+	fmt.Printf("⏪ Rolling back %s run from %s (%s)\n", j.Tool, j.Started.Format("2006-01-02 15:04:05"), j.Dir)
+	steps, err := j.Rollback()
+	for _, s := range steps {
+		fmt.Println("✅", s)
+	}
+	if err != nil {
+		return fmt.Errorf("rollback failed: %w", err)
+	}
+	fmt.Println("🎉 Rollback complete.")
+	return nil
+}
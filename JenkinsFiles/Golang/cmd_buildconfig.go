@@ -0,0 +1,169 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/changeset"
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/cocos"
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/config"
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/pbxproj"
+)
+
+var cocosBuildConfigCmd = &command{
+	group:   "cocos",
+	name:    "build-config",
+	summary: "Generate buildConfig_ios.json from the Unity app's bundle id, name and team",
+}
+
+func init() {
+	cocosBuildConfigCmd.run = runCocosBuildConfig
+	register(cocosBuildConfigCmd)
+}
+
+// unityAppTarget is the app target of a Unity iOS export.
+const unityAppTarget = "Unity-iPhone"
+
+// buildConfigFlags are the inputs of buildConfig_ios.json. Empty bundle id,
+// product name and team are read from the Unity Xcode project.
+type buildConfigFlags struct {
+	bundleID, productName, teamID *string
+	targetVersion, orientation    *string
+	startScene, scenes            *string
+}
+
+func bindBuildConfigFlags(fs *flag.FlagSet) buildConfigFlags {
+	return buildConfigFlags{
+		bundleID:      fs.String("bundle-id", "", "bundle identifier (default: PRODUCT_BUNDLE_IDENTIFIER of the Unity app)"),
+		productName:   fs.String("product-name", "", "product name (default: -product, then PRODUCT_NAME of the Unity app)"),
+		teamID:        fs.String("team-id", "", "development team ID (default: DEVELOPMENT_TEAM of the Unity app)"),
+		targetVersion: fs.String("ios-version", cocos.DefaultTargetVersion, "minimum iOS version"),
+		orientation:   fs.String("orientation", "all", "supported orientations: all, portrait, upside-down, landscape, landscape-left, landscape-right, comma-separated"),
+		startScene:    fs.String("start-scene", "", "start scene by name, db:// URL or UUID (default: the only scene)"),
+		scenes:        fs.String("scenes", "", "comma-separated scenes to build (default: all scenes)"),
+	}
+}
+
+// writeBuildConfig generates project's buildConfig_ios.json.
+func (f buildConfigFlags) writeBuildConfig(plan *changeset.Plan, cfg *config.Config, project *cocos.Project) error {
+	path := project.BuildConfigPath()
+	if path == "" {
+		fmt.Printf("ℹ️ Creator %s reads its settings from builder.json, no build config to generate.\n", project.Version)
+		return nil
+	}
+
+	orientation, err := cocos.ParseOrientation(*f.orientation)
+	if err != nil {
+		return err
+	}
+	productName := *f.productName
+	if productName == "" {
+		productName = cfg.Product
+	}
+	in := cocos.BuildConfigInputs{
+		BundleID:      *f.bundleID,
+		ProductName:   productName,
+		TeamID:        *f.teamID,
+		TargetVersion: *f.targetVersion,
+		Orientation:   orientation,
+		StartScene:    *f.startScene,
+	}
+	for _, s := range strings.Split(*f.scenes, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			in.Scenes = append(in.Scenes, s)
+		}
+	}
+	if in.BundleID == "" || in.ProductName == "" || in.TeamID == "" {
+		if err := readUnityAppSettings(cfg, &in); err != nil {
+			return err
+		}
+	}
+
+	buildConfig, err := project.NewBuildConfig(in)
+	if err != nil {
+		return err
+	}
+	data, err := buildConfig.Marshal()
+	if err != nil {
+		return err
+	}
+	ios := buildConfig.Packages.IOS
+	fmt.Printf("✅ Build config: %s (%s), team %q, iOS %s, %d scene(s), start %s\n",
+		buildConfig.Name, ios.PackageName, ios.DeveloperTeam, ios.TargetVersion, len(buildConfig.Scenes), buildConfig.StartScene)
+	return plan.WriteFile(path, data, 0644)
+}
+
+// readUnityAppSettings fills the inputs left empty from the Release
+// configuration of the Unity app target.
+func readUnityAppSettings(cfg *config.Config, in *cocos.BuildConfigInputs) error {
+	xcodeProj, err := discoverXcodeProj(os.Stdout, "Unity Xcode project", cfg.UnityProject, cfg.UnityXcodeProj, "")
+	if err != nil {
+		return fmt.Errorf("%w (or pass -bundle-id, -product-name and -team-id)", err)
+	}
+	project, err := loadPbxproj(xcodeProj)
+	if err != nil {
+		return err
+	}
+	target, err := project.TargetByName(unityAppTarget)
+	if err != nil {
+		return fmt.Errorf("Unity app target: %w", err)
+	}
+	targetConfigs, err := project.Configurations(target, "Release")
+	if err != nil {
+		return fmt.Errorf("Unity app target: %w", err)
+	}
+	levels := []*pbxproj.XCBuildConfiguration{targetConfigs[0]}
+	if projectConfigs, err := project.Configurations(nil, "Release"); err == nil {
+		levels = append(levels, projectConfigs[0])
+	}
+	// Settings Xcode defines itself.
+	levels = append(levels, &pbxproj.XCBuildConfiguration{BuildSettings: map[string]interface{}{"TARGET_NAME": target.Name}})
+
+	for _, s := range []struct {
+		value *string
+		keys  []string
+	}{
+		{&in.BundleID, []string{"PRODUCT_BUNDLE_IDENTIFIER"}},
+		// Unity keeps the player's product name in PRODUCT_NAME_APP.
+		{&in.ProductName, []string{"PRODUCT_NAME_APP", "PRODUCT_NAME"}},
+		{&in.TeamID, []string{"DEVELOPMENT_TEAM"}},
+	} {
+		for _, key := range s.keys {
+			if *s.value != "" {
+				break
+			}
+			if v, ok := pbxproj.ExpandSetting(key, levels...); ok && !strings.Contains(v, "$") {
+				*s.value = v
+				fmt.Printf("ℹ️ %s from %s: %s\n", key, unityAppTarget, v)
+			}
+		}
+	}
+	return nil
+}
+
+func runCocosBuildConfig(args []string) error {
+	fs := newFlagSet(cocosBuildConfigCmd, "")
+	flags := bindPlanFlags(fs)
+	inputs := bindBuildConfigFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	cfg, plan, err := flags.open(cocosBuildConfigCmd, config.Config{
+		BaseDir:      executableDir(),
+		CocosProject: "cocosProject",
+		UnityProject: "UnityBuild",
+	})
+	if err != nil {
+		return err
+	}
+	defer plan.Close()
+	defer plan.WriteText(os.Stdout)
+
+	project, err := openCocosProject(cfg)
+	if err != nil {
+		return err
+	}
+	return inputs.writeBuildConfig(plan, cfg, project)
+}
@@ -0,0 +1,435 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/cocos"
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/cocoslog"
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/config"
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/pbxproj"
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/runner"
+)
+
+var cocosBuildCmd = &command{
+	group:   "cocos",
+	name:    "build",
+	summary: "Build the Cocos Creator project, add it to the workspace and sync the icons",
+}
+
+var cocosParseLogCmd = &command{
+	group:   "cocos",
+	name:    "parse-log",
+	summary: "Summarize a Cocos Creator build log: stages, warnings and the errors that failed it",
+}
+
+var cocosPatchXcodeCmd = &command{
+	group:   "cocos",
+	name:    "patch-xcode",
+	summary: "Embed UnityFramework.framework in the Cocos Xcode project",
+}
+
+func init() {
+	cocosBuildCmd.run = runCocosBuild
+	cocosParseLogCmd.run = runCocosParseLog
+	cocosPatchXcodeCmd.run = runCocosPatchXcode
+	register(cocosBuildCmd)
+	register(cocosParseLogCmd)
+	register(cocosPatchXcodeCmd)
+}
+
+// maxLogProblems caps the errors and warnings printed from a build log; the
+// JSON result has them all.
+const maxLogProblems = 20
+
+func runCocosBuild(args []string) error {
+	fs := newFlagSet(cocosBuildCmd, "")
+	flags := bindPlanFlags(fs)
+	inputs := bindBuildConfigFlags(fs)
+	clean := fs.Bool("clean", false, "delete library and temp even when the import cache is still valid")
+	timeout := fs.Duration("timeout", 60*time.Minute, "stop the Creator build after this long, 0 for no limit")
+	idleTimeout := fs.Duration("idle-timeout", 15*time.Minute, "stop the Creator build after this long without output, 0 for no limit")
+	iconsMode := bindIconsModeFlag(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := checkIconsMode(*iconsMode); err != nil {
+		return err
+	}
+	cfg, plan, err := flags.open(cocosBuildCmd, config.Config{
+		BaseDir:      executableDir(),
+		CocosProject: "cocosProject",
+		UnityProject: "UnityBuild",
+		AssetSets:    defaultAssetSets,
+		WorkspaceDir: "XcodeWorkspace",
+	})
+	if err != nil {
+		return err
+	}
+	defer plan.Close()
+	defer plan.WriteText(os.Stdout)
+
+	project, err := openCocosProject(cfg)
+	if err != nil {
+		return err
+	}
+	if err := project.Check(); err != nil {
+		return err
+	}
+	creatorPath := cfg.CreatorPath
+	if creatorPath == "" {
+		creatorPath = project.CreatorPath()
+	}
+	fmt.Println("ℹ️ Cocos Creator:", creatorPath)
+
+	// Step 0: Generate the build config for this game
+	if err := inputs.writeBuildConfig(plan, cfg, project); err != nil {
+		return err
+	}
+
+	// Step 1: Clean the output, and the imported assets when they are stale
+	stamp, err := project.CacheStamp()
+	if err != nil {
+		return err
+	}
+	outputDir, err := project.OutputDir()
+	if err != nil {
+		return err
+	}
+	folders := []string{outputDir}
+	reasons := []string{"-clean given"}
+	if !*clean {
+		if reasons, err = project.StaleCache(stamp); err != nil {
+			return err
+		}
+	}
+	if len(reasons) > 0 {
+		for _, reason := range reasons {
+			fmt.Println("♻️ Cache invalid:", reason)
+		}
+		for _, folder := range project.Profile.CacheDirs {
+			folders = append(folders, filepath.Join(project.Dir, folder))
+		}
+	} else {
+		fmt.Println("♻️ Reusing", strings.Join(project.Profile.CacheDirs, " and "), "(assets, settings and Creator version unchanged).")
+	}
+	for _, folder := range folders {
+		fmt.Printf("🧹 Removing folder: %s\n", folder)
+		if err := plan.Discard(folder); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("✅ Cleaned %s.\n", strings.Join(folders, ", "))
+
+	// Step 2: Give Creator's file watchers a moment after a full clean
+	if len(reasons) > 0 && !plan.DryRun {
+		time.Sleep(2 * time.Second)
+	}
+
+	// Step 3: Build cocos project, streaming its output to the console and the log
+	logFile := filepath.Join(cfg.BaseDir, "cocos_build.log")
+	buildCmd := exec.Command(creatorPath, project.BuildArgs()...)
+	parser := cocoslog.NewParser()
+	fmt.Println("🚀 Building Cocos project...")
+	err = plan.Run(strings.Join(buildCmd.Args, " ")+" > "+logFile, func() error {
+		logF, err := os.Create(logFile)
+		if err != nil {
+			return err
+		}
+		defer logF.Close()
+		return runner.Run(context.Background(), buildCmd, runner.Options{
+			Console:     os.Stdout,
+			Log:         logF,
+			OnLine:      parser.Line,
+			Timeout:     *timeout,
+			IdleTimeout: *idleTimeout,
+		})
+	})
+
+	// Step 4: Check the parsed log for the outcome and the reason of a failure
+	if !plan.DryRun {
+		var exitErr *exec.ExitError
+		stopped := errors.Is(err, runner.ErrTimeout) || errors.Is(err, runner.ErrInterrupted)
+		if err != nil && !stopped && !errors.As(err, &exitErr) {
+			// Creator never ran, e.g. a wrong path, or the log could not be created.
+			return fmt.Errorf("failed to run Cocos Creator: %w", err)
+		}
+		if exitErr != nil {
+			if meaning := cocoslog.DescribeExitCode(exitErr.ExitCode()); meaning != "" {
+				fmt.Printf("ℹ️ Cocos Creator exited with %d (%s).\n", exitErr.ExitCode(), meaning)
+			}
+		}
+		result := parser.Result()
+		result.WriteSummary(os.Stdout, maxLogProblems)
+		resultFile := filepath.Join(cfg.BaseDir, "cocos_build.json")
+		if err := result.WriteJSON(resultFile); err != nil {
+			fmt.Println("⚠️ Failed to write build result:", err)
+		} else {
+			fmt.Println("📝 Build result written to", resultFile)
+		}
+		if stopped {
+			return fmt.Errorf("Cocos build stopped: %w", err)
+		}
+		if !result.Success {
+			return fmt.Errorf("Cocos build failed: %s", result.Reason())
+		}
+		fmt.Println("✅ Cocos project build finished (build success detected).")
+	}
+
+	// Remember what library/ was built from, for the next run
+	stampData, err := stamp.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := plan.WriteFile(project.CacheStampPath(), stampData, 0644); err != nil {
+		return fmt.Errorf("failed to write cache stamp: %w", err)
+	}
+
+	// Step 5: Find .xcodeproj and add to workspace
+	product := cocosProduct(cfg, project)
+	xcodeProjPath, err := findCocosXcodeProj(cfg, project, product)
+	switch {
+	case err != nil && plan.DryRun:
+		// The build was skipped, so there may be no project to add yet.
+		fmt.Printf("ℹ️ %v, skipping the workspace step.\n", err)
+	case err != nil:
+		return err
+	default:
+		if err := addToWorkspace(plan, cfg, product, xcodeProjPath); err != nil {
+			return err
+		}
+	}
+
+	// Step 6: Carry the Unity icons and other asset sets over to Cocos
+	return syncAssets(plan, cfg, project, *iconsMode)
+}
+
+// openCocosProject detects the Creator version of the configured project.
+func openCocosProject(cfg *config.Config) (*cocos.Project, error) {
+	project, err := cocos.Open(cfg.CocosProject, cfg.CocosVersion)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("ℹ️ Cocos project:", project.Dir, "-", project)
+	return project, nil
+}
+
+func runCocosParseLog(args []string) error {
+	fs := newFlagSet(cocosParseLogCmd, "<cocos_build.log>")
+	jsonPath := fs.String("json", "", "also write the result as JSON to this file")
+	max := fs.Int("max", maxLogProblems, "errors and warnings to print, 0 for all")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageErrorf("need exactly one log file")
+	}
+
+	result, err := cocoslog.ParseFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	result.WriteSummary(os.Stdout, *max)
+	if *jsonPath != "" {
+		if err := result.WriteJSON(*jsonPath); err != nil {
+			return err
+		}
+		fmt.Println("📝 Build result written to", *jsonPath)
+	}
+	if !result.Success {
+		return exitCode(exitFindings)
+	}
+	return nil
+}
+
+func runCocosPatchXcode(args []string) (err error) {
+	fs := newFlagSet(cocosPatchXcodeCmd, "")
+	flags := bindPlanFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	closeLog, err := logToFile("/tmp/cocos_xcode_patch.log")
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+	defer func() {
+		if err != nil {
+			log.Println("❌", err)
+		}
+	}()
+
+	cwd, _ := os.Getwd()
+	log.Println("📁 Working directory:", cwd)
+
+	// Define project paths
+	cfg, plan, err := flags.open(cocosPatchXcodeCmd, config.Config{
+		BaseDir:       cwd,
+		CocosXcodeDir: "CocosBuild/jsb-default/frameworks/runtime-src/proj.ios_mac",
+		UnityProject:  "UnityBuild",
+	})
+	if err != nil {
+		return err
+	}
+	defer plan.Close()
+	log.Print("⚙️ Configuration:\n", cfg)
+
+	cocosXcodeProj, err := discoverXcodeProj(log.Writer(), "Cocos Xcode project", cfg.CocosXcodeDir, cfg.CocosXcodeProj, cfg.Product)
+	if err != nil {
+		return err
+	}
+	unityXcodeProj, err := discoverXcodeProj(log.Writer(), "Unity Xcode project", cfg.UnityProject, cfg.UnityXcodeProj, "")
+	if err != nil {
+		return err
+	}
+
+	// Load both Xcode projects
+	cocosProject, err := loadPbxproj(cocosXcodeProj)
+	if err != nil {
+		return err
+	}
+	unityProject, err := loadPbxproj(unityXcodeProj)
+	if err != nil {
+		return err
+	}
+	original, err := cocosProject.Clone()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot pbxproj: %w", err)
+	}
+
+	// Step 1: Find UnityFramework.framework fileRef from Unity project
+	unityFrameworkRef, err := unityProject.FileReferenceByPath("UnityFramework.framework")
+	if err != nil {
+		return fmt.Errorf("UnityFramework.framework not found in Unity project: %w", err)
+	}
+	log.Println("📦 Found UnityFramework.framework fileRef:", unityFrameworkRef.ID())
+
+	// Step 2: Add fileRef to Cocos if not exists
+	cocosFrameworkRef, err := ensureFileReferenceExists(cocosProject, "Frameworks", "UnityFramework.framework", pbxproj.SourceTreeSourceRoot, "wrapper.framework")
+	if err != nil {
+		return fmt.Errorf("failed to add UnityFramework.framework to Cocos project: %w", err)
+	}
+	log.Println("📎 Reusing or created fileRef put in Cocos:", cocosFrameworkRef)
+
+	// Step 3: Find first native target
+	target, err := findFirstNativeTarget(cocosProject)
+	if err != nil {
+		return fmt.Errorf("could not find suitable native target (non-desktop): %w", err)
+	}
+	log.Println("🎯 Using target:", target.Name)
+
+	// Step 4: Embed it, and Step 5: remove it from Link Binary With Libraries
+	if err := embedFramework(cocosProject, target, cocosFrameworkRef, "UnityFramework.framework"); err != nil {
+		return err
+	}
+
+	if err := plan.SaveProject(config.PbxprojPath(cocosXcodeProj), cocosProject, original); err != nil {
+		return fmt.Errorf("failed to write pbxproj: %w", err)
+	}
+
+	plan.WriteText(log.Writer())
+	if plan.DryRun {
+		plan.WriteText(os.Stdout)
+		return nil
+	}
+	log.Println("🎉 Cocos Xcode project patched successfully.")
+	fmt.Println("🎉 Cocos Xcode project patched successfully.")
+	return nil
+}
+
+// ensureFileReferenceExists returns the reference for file, creating it under
+// groupPath in the project navigator if needed. Like the first version of
+// this tool, any reference to a file of the same name is reused, wherever it
+// lives (BUILT_PRODUCTS_DIR, a group with its own path, ...): adding a
+// second one would leave the original linked next to the embedded copy.
+func ensureFileReferenceExists(project *pbxproj.Project, groupPath, file, sourceTree, fileType string) (string, error) {
+	name := path.Base(file)
+	if refs := fileReferencesNamed(project, name); len(refs) > 0 {
+		if len(refs) > 1 {
+			log.Printf("⚠️ %d file references to %s, using %s", len(refs), name, refs[0].ID())
+		}
+		return refs[0].ID(), nil
+	}
+	ref, err := project.AddFileReference(groupPath, pbxproj.FileSpec{
+		Path:       file,
+		SourceTree: sourceTree,
+		FileType:   fileType,
+		Explicit:   true,
+	})
+	if err != nil {
+		return "", err
+	}
+	return ref.ID(), nil
+}
+
+// fileReferencesNamed returns the file references whose file is name, by
+// name or by the last component of their path, ordered by ID.
+func fileReferencesNamed(project *pbxproj.Project, name string) []*pbxproj.PBXFileReference {
+	var refs []*pbxproj.PBXFileReference
+	for _, id := range project.IDs() {
+		ref, ok := project.Objects[id].(*pbxproj.PBXFileReference)
+		if !ok {
+			continue
+		}
+		if ref.Name == name || (ref.Path != "" && path.Base(ref.Path) == name) {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+func findFirstNativeTarget(project *pbxproj.Project) (*pbxproj.PBXNativeTarget, error) {
+	targets, err := project.NativeTargets()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range targets {
+		// Skip desktop, test, or mac-related targets
+		lower := strings.ToLower(t.Name)
+		if strings.Contains(lower, "desktop") || strings.Contains(lower, "test") || strings.Contains(lower, "mac") {
+			continue
+		}
+		return t, nil
+	}
+	return nil, fmt.Errorf("no iOS target among %d native targets", len(targets))
+}
+
+// embedFramework adds the framework refID, named name in the log, to the
+// Embed Frameworks phase of target and removes it from the phases that link
+// it: an embedded framework is linked through its embedded copy.
+func embedFramework(project *pbxproj.Project, target *pbxproj.PBXNativeTarget, refID, name string) error {
+	embedPhase, err := project.EnsurePhase(target, "embed-frameworks")
+	if err != nil {
+		return fmt.Errorf("failed to find Embed Frameworks phase: %w", err)
+	}
+	settings := map[string]interface{}{
+		"ATTRIBUTES": []interface{}{"CodeSignOnCopy", "RemoveHeadersOnCopy"},
+	}
+	if _, added, err := project.AddToPhase(embedPhase, refID, settings); err != nil {
+		return fmt.Errorf("failed to embed %s: %w", name, err)
+	} else if added {
+		log.Println("✅ Added", name, "to Embed Frameworks")
+	} else {
+		log.Println("ℹ️", name, "already added to phase")
+	}
+
+	linkPhases, err := project.TargetBuildPhasesOf(target, "PBXFrameworksBuildPhase")
+	if err != nil {
+		return fmt.Errorf("failed to unlink %s: %w", name, err)
+	}
+	for _, phase := range linkPhases {
+		for _, id := range project.RemoveFromPhase(phase, refID) {
+			log.Println("🗑 Removed", name, "from Link Binary With Libraries:", id)
+		}
+	}
+	return nil
+}
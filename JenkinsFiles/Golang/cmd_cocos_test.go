@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/pbxproj"
+)
+
+// cocosProject is a trimmed Cocos project that already links a
+// UnityFramework.framework built into BUILT_PRODUCTS_DIR.
+const cocosProject = `// !$*UTF8*$!
+{
+	archiveVersion = 1;
+	classes = {
+	};
+	objectVersion = 56;
+	objects = {
+		B1 = {isa = PBXBuildFile; fileRef = F1; };
+		F1 = {isa = PBXFileReference; explicitFileType = wrapper.framework; path = UnityFramework.framework; sourceTree = BUILT_PRODUCTS_DIR; };
+		G0 = {isa = PBXGroup; children = (G1, ); sourceTree = "<group>"; };
+		G1 = {isa = PBXGroup; children = (F1, ); name = Frameworks; sourceTree = "<group>"; };
+		K1 = {isa = PBXFrameworksBuildPhase; buildActionMask = 2147483647; files = (B1, ); runOnlyForDeploymentPostprocessing = 0; };
+		T1 = {isa = PBXNativeTarget; buildConfigurationList = L1; buildPhases = (K1, ); name = "Game-mobile"; productName = Game; productType = "com.apple.product-type.application"; };
+		T2 = {isa = PBXNativeTarget; buildConfigurationList = L2; buildPhases = ( ); name = "Game-desktop"; productName = Game; productType = "com.apple.product-type.application"; };
+		C1 = {isa = XCBuildConfiguration; buildSettings = {}; name = Release; };
+		C2 = {isa = XCBuildConfiguration; buildSettings = {}; name = Release; };
+		C3 = {isa = XCBuildConfiguration; buildSettings = {}; name = Release; };
+		L1 = {isa = XCConfigurationList; buildConfigurations = (C1, ); };
+		L2 = {isa = XCConfigurationList; buildConfigurations = (C2, ); };
+		L3 = {isa = XCConfigurationList; buildConfigurations = (C3, ); };
+		PR = {isa = PBXProject; buildConfigurationList = L3; mainGroup = G0; targets = (T2, T1, ); };
+	};
+	rootObject = PR;
+}
+`
+
+func parseCocosProject(t *testing.T) *pbxproj.Project {
+	t.Helper()
+	project, err := pbxproj.Parse([]byte(cocosProject), "Game")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return project
+}
+
+func TestEnsureFileReferenceReusesFrameworkByName(t *testing.T) {
+	project := parseCocosProject(t)
+	id, err := ensureFileReferenceExists(project, "Frameworks", "UnityFramework.framework", pbxproj.SourceTreeSourceRoot, "wrapper.framework")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "F1" {
+		t.Errorf("got %s, want the existing BUILT_PRODUCTS_DIR reference F1", id)
+	}
+	if refs := fileReferencesNamed(project, "UnityFramework.framework"); len(refs) != 1 {
+		t.Errorf("%d references to UnityFramework.framework, want 1", len(refs))
+	}
+}
+
+func TestEmbedFrameworkMovesItOutOfTheLinkPhase(t *testing.T) {
+	project := parseCocosProject(t)
+	target, err := findFirstNativeTarget(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target.Name != "Game-mobile" {
+		t.Fatalf("target %q, want Game-mobile", target.Name)
+	}
+	for i := 0; i < 2; i++ {
+		if err := embedFramework(project, target, "F1", "UnityFramework.framework"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	embed, err := project.FindPhase(target, "embed-frameworks")
+	if err != nil || embed == nil {
+		t.Fatalf("no Embed Frameworks phase: %v", err)
+	}
+	files, err := project.PhaseBuildFiles(embed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].FileRef != "F1" || files[0].Settings["ATTRIBUTES"] == nil {
+		t.Errorf("Embed Frameworks = %+v, want F1 once with its ATTRIBUTES", files)
+	}
+	link, _ := project.BuildPhase("K1")
+	if len(link.Files) != 0 {
+		t.Errorf("Link phase still has %v", link.Files)
+	}
+	if _, ok := project.Objects["B1"]; ok {
+		t.Error("the unlinked build file B1 is still in the project")
+	}
+}
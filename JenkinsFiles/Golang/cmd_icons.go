@@ -0,0 +1,277 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"os"
+	"path/filepath"
+
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/changeset"
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/config"
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/xcassets"
+)
+
+var iconsSyncCmd = &command{
+	group:   "icons",
+	name:    "sync",
+	summary: "Merge the Unity AppIcon set into the Cocos one, or replace it",
+}
+
+var iconsValidateCmd = &command{
+	group:   "icons",
+	name:    "validate",
+	summary: "Check AppIcon sets against their Contents.json",
+}
+
+var iconsRegenerateCmd = &command{
+	group:   "icons",
+	name:    "regenerate",
+	summary: "Generate a complete AppIcon set from one 1024x1024 image",
+}
+
+func init() {
+	iconsSyncCmd.run = runIconsSync
+	iconsValidateCmd.run = runIconsValidate
+	iconsRegenerateCmd.run = runIconsRegenerate
+	register(iconsSyncCmd)
+	register(iconsValidateCmd)
+	register(iconsRegenerateCmd)
+}
+
+// unityIconSet is the AppIcon set of the Unity iOS export.
+func unityIconSet(cfg *config.Config) string {
+	return filepath.Join(unityAssetCatalog(cfg), "AppIcon.appiconset")
+}
+
+// Modes of icons sync.
+const (
+	// iconsMerge fills each slot from Unity, or from Cocos where Unity has
+	// no valid image for it.
+	iconsMerge = "merge"
+	// iconsReplace swaps the whole Cocos set for Unity's.
+	iconsReplace = "replace"
+)
+
+func bindIconsModeFlag(fs *flag.FlagSet) *string {
+	return fs.String("icons", iconsMerge, "how the Unity app icons reach the Cocos set: merge (slot by slot, keeping Cocos-only slots) or replace")
+}
+
+func checkIconsMode(mode string) error {
+	if mode != iconsMerge && mode != iconsReplace {
+		return usageErrorf("unknown -icons mode %q (want %s or %s)", mode, iconsMerge, iconsReplace)
+	}
+	return nil
+}
+
+func runIconsSync(args []string) error {
+	fs := newFlagSet(iconsSyncCmd, "")
+	flags := bindPlanFlags(fs)
+	mode := bindIconsModeFlag(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := checkIconsMode(*mode); err != nil {
+		return err
+	}
+	cfg, plan, err := flags.open(iconsSyncCmd, config.Config{
+		BaseDir:      executableDir(),
+		CocosProject: "cocosProject",
+		UnityProject: "UnityBuild",
+	})
+	if err != nil {
+		return err
+	}
+	defer plan.Close()
+	defer plan.WriteText(os.Stdout)
+	project, err := openCocosProject(cfg)
+	if err != nil {
+		return err
+	}
+	return syncIcons(plan, unityIconSet(cfg), project.IconSet(), *mode)
+}
+
+// syncIcons brings the Unity icon set into the Cocos project, merged slot
+// by slot or replacing the whole set, depending on mode.
+func syncIcons(plan *changeset.Plan, unityIcons, cocosIcons, mode string) error {
+	name := filepath.Base(unityIcons)
+
+	// Ensure source exists
+	srcInfo, err := os.Stat(unityIcons)
+	if err != nil || !srcInfo.IsDir() {
+		return fmt.Errorf("Unity %s not found at %s", name, unityIcons)
+	}
+
+	if mode == iconsMerge {
+		return mergeIcons(plan, unityIcons, cocosIcons)
+	}
+
+	// Do not ship a set Xcode or App Store Connect would reject
+	if errors, _, err := reportAssetSet(unityIcons); err != nil {
+		return err
+	} else if errors > 0 {
+		return fmt.Errorf("Unity %s has %d error(s)", name, errors)
+	}
+
+	// Delete the target folder if it exists, then copy the entire folder
+	if err := plan.RemoveAll(cocosIcons); err != nil {
+		return fmt.Errorf("failed to remove Cocos icon set: %w", err)
+	}
+	if err := plan.CopyDir(unityIcons, cocosIcons); err != nil {
+		return fmt.Errorf("failed to copy icon set folder: %w", err)
+	}
+	fmt.Printf("✅ Entire Unity %s replaced Cocos icon set.\n", name)
+	return nil
+}
+
+// mergeIcons rewrites the Cocos icon set as the union of both sets,
+// preferring Unity's image for every slot where it is valid.
+func mergeIcons(plan *changeset.Plan, unityIcons, cocosIcons string) error {
+	sets := []string{unityIcons}
+	if _, err := os.Stat(filepath.Join(cocosIcons, xcassets.ContentsFile)); err == nil {
+		sets = append(sets, cocosIcons)
+	} else {
+		fmt.Println("ℹ️ No Cocos icon set yet, taking Unity's as is.")
+	}
+	merged, err := xcassets.MergeIconSets(sets...)
+	if err != nil {
+		return err
+	}
+
+	fromUnity, broken := 0, 0
+	for _, c := range merged.Choices {
+		switch {
+		case len(c.Issues) > 0:
+			broken++
+			for _, issue := range c.Issues {
+				fmt.Println("❌", issue, "in", c.Source)
+			}
+		case c.Source == unityIcons:
+			fromUnity++
+		default:
+			fmt.Printf("ℹ️ %s kept from Cocos (%s)\n", c.Slot, c.File)
+		}
+	}
+	for _, slot := range merged.Missing {
+		fmt.Printf("⚠️ %s: no image in either set\n", slot)
+	}
+	if broken > 0 {
+		return fmt.Errorf("%d icon slot(s) have no valid image in either set", broken)
+	}
+
+	if err := replaceIconSet(plan, cocosIcons, merged.WriteTo); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Icon sets merged: %d slot(s) from Unity, %d from Cocos, %d missing.\n",
+		fromUnity, len(merged.Choices)-fromUnity, len(merged.Missing))
+	return nil
+}
+
+// replaceIconSet has write build the new set in a scratch folder, then
+// swaps it in for dir, so the set never holds files its Contents.json
+// does not list.
+func replaceIconSet(plan *changeset.Plan, dir string, write func(tmp string) error) error {
+	tmp, err := os.MkdirTemp("", "appiconset-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	if err := write(tmp); err != nil {
+		return err
+	}
+	if err := plan.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove icon set: %w", err)
+	}
+	if err := plan.CopyDir(tmp, dir); err != nil {
+		return fmt.Errorf("failed to write icon set: %w", err)
+	}
+	return nil
+}
+
+func runIconsValidate(args []string) error {
+	fs := newFlagSet(iconsValidateCmd, "[AppIcon.appiconset...]")
+	strict := fs.Bool("strict", false, "treat warnings (empty slots, unlisted files) as errors")
+	cfgFlags := config.Bind(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	sets := fs.Args()
+	if len(sets) == 0 {
+		// Both ends of icons sync.
+		cfg, err := cfgFlags.Resolve(config.Config{
+			BaseDir:      executableDir(),
+			CocosProject: "cocosProject",
+			UnityProject: "UnityBuild",
+		})
+		if err != nil {
+			return err
+		}
+		project, err := openCocosProject(cfg)
+		if err != nil {
+			return err
+		}
+		sets = []string{unityIconSet(cfg), project.IconSet()}
+	}
+
+	return validateAssetSets(sets, *strict)
+}
+
+func runIconsRegenerate(args []string) error {
+	fs := newFlagSet(iconsRegenerateCmd, "[AppIcon.appiconset]")
+	source := fs.String("source", "", "square PNG or JPEG of at least 1024x1024 pixels")
+	flags := bindPlanFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *source == "" {
+		return usageErrorf("-source is required")
+	}
+	if fs.NArg() > 1 {
+		return usageErrorf("at most one icon set can be regenerated")
+	}
+	cfg, plan, err := flags.open(iconsRegenerateCmd, config.Config{
+		BaseDir:      executableDir(),
+		CocosProject: "cocosProject",
+	})
+	if err != nil {
+		return err
+	}
+	defer plan.Close()
+	defer plan.WriteText(os.Stdout)
+
+	var dir string
+	if fs.NArg() == 1 {
+		dir = cfg.Resolve(fs.Arg(0))
+	} else {
+		project, err := openCocosProject(cfg)
+		if err != nil {
+			return err
+		}
+		dir = project.IconSet()
+	}
+
+	f, err := os.Open(cfg.Resolve(*source))
+	if err != nil {
+		return err
+	}
+	master, _, err := image.Decode(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to decode %s: %w", *source, err)
+	}
+	set, err := xcassets.GenerateIconSet(master, xcassets.StandardIconSlots)
+	if err != nil {
+		return err
+	}
+	if set.Flattened {
+		fmt.Println("⚠️ The source icon has transparent pixels; they were put over white.")
+	}
+
+	if err := replaceIconSet(plan, dir, set.WriteTo); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Generated %d icon slot(s) in %d file(s) into %s\n", len(set.Contents.Images), len(set.Files), dir)
+	return nil
+}
@@ -0,0 +1,428 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/changeset"
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/config"
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/pbxproj"
+)
+
+var unityPatchXcodeCmd = &command{
+	group:   "unity",
+	name:    "patch-xcode",
+	summary: "Move Data and public headers to UnityFramework and patch the Unity Xcode project",
+}
+
+func init() {
+	unityPatchXcodeCmd.run = runUnityPatchXcode
+	register(unityPatchXcodeCmd)
+}
+
+func runUnityPatchXcode(args []string) (err error) {
+	fs := newFlagSet(unityPatchXcodeCmd, "")
+	flags := bindPlanFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	closeLog, err := logToFile("/tmp/unity_xcode_patch.log")
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+	defer func() {
+		if err != nil {
+			log.Println("❌", err)
+		}
+	}()
+
+	cwd, _ := os.Getwd()
+	log.Println("📁 Working directory:", cwd)
+
+	cfg, plan, err := flags.open(unityPatchXcodeCmd, config.Config{
+		BaseDir:          cwd,
+		UnityProject:     "UnityBuild",
+		HeaderVisibility: defaultHeaderVisibility,
+	})
+	if err != nil {
+		return err
+	}
+	defer plan.Close()
+	log.Print("⚙️ Configuration:\n", cfg)
+	rules, err := headerRules(cfg.HeaderVisibility)
+	if err != nil {
+		return usageErrorf("-header-visibility: %v", err)
+	}
+
+	unityXcodeProj, err := discoverXcodeProj(log.Writer(), "Unity Xcode project", cfg.UnityProject, cfg.UnityXcodeProj, "")
+	if err != nil {
+		return err
+	}
+	dataFolder := "Data"
+	targetName := "UnityFramework"
+
+	project, err := loadPbxproj(unityXcodeProj)
+	if err != nil {
+		return err
+	}
+	original, err := project.Clone()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot pbxproj: %w", err)
+	}
+
+	target, err := project.TargetByName(targetName)
+	if err != nil {
+		return fmt.Errorf("UnityFramework target not found: %w", err)
+	}
+	log.Println("🎯 Found UnityFramework target:", target.ID())
+
+	if err := moveDataToFramework(project, filepath.Dir(unityXcodeProj), dataFolder, target); err != nil {
+		return fmt.Errorf("failed to move Data to UnityFramework: %w", err)
+	}
+
+	if err := updateHeaderVisibility(project, target, rules); err != nil {
+		return fmt.Errorf("header visibility update failed: %w", err)
+	}
+
+	uiFilePath := filepath.Join(cfg.UnityProject, "Classes", "UI", "UnityViewControllerBase+iOS.mm")
+	if err := patchShouldAutorotate(plan, uiFilePath); err != nil {
+		return err
+	}
+
+	// ✅ Overwrite PrivacyInfo.xcprivacy from local working dir to UnityFramework folder
+	if err := overwritePrivacyInfo(plan, cfg); err != nil {
+		return fmt.Errorf("failed to overwrite PrivacyInfo.xcprivacy: %w", err)
+	}
+
+	if err := plan.SaveProject(config.PbxprojPath(unityXcodeProj), project, original); err != nil {
+		return fmt.Errorf("failed to save project: %w", err)
+	}
+
+	plan.WriteText(log.Writer())
+	if plan.DryRun {
+		plan.WriteText(os.Stdout)
+		return nil
+	}
+
+	log.Println("🎉 Unity Xcode project patched successfully.")
+	fmt.Println("🎉 Unity Xcode project patched successfully.")
+	return nil
+}
+
+func overwritePrivacyInfo(plan *changeset.Plan, cfg *config.Config) error {
+	src := filepath.Join(cfg.BaseDir, "PrivacyInfo.xcprivacy")
+	dst := filepath.Join(cfg.UnityProject, "UnityFramework", "PrivacyInfo.xcprivacy")
+
+	log.Println("📂 Overwriting PrivacyInfo.xcprivacy")
+	log.Println("📄 Source:", src)
+	log.Println("📄 Destination:", dst)
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("Failed to read override file: %w", err)
+	}
+
+	err = plan.WriteFile(dst, data, 0644)
+	if err != nil {
+		return fmt.Errorf("Failed to write to destination: %w", err)
+	}
+	log.Println("✅ Overwrote PrivacyInfo.xcprivacy successfully")
+	return nil
+}
+
+// moveDataToFramework makes Unity's Data folder a resource of target
+// instead of Unity-iPhone, logging which phases list it before and after.
+// sourceRoot is the folder holding the Xcode project, where Data must exist.
+func moveDataToFramework(project *pbxproj.Project, sourceRoot, dataFolder string, target *pbxproj.PBXNativeTarget) error {
+	dir := filepath.Join(sourceRoot, dataFolder)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("Data folder not found at %s", dir)
+	}
+
+	ref, err := ensureDataFileReference(project, dataFolder)
+	if err != nil {
+		return fmt.Errorf("failed to add Data file reference: %w", err)
+	}
+	before, err := phaseMembership(project, ref.ID())
+	if err != nil {
+		return err
+	}
+	log.Println("📋 Data before:", before)
+
+	if err := removeDataFromTarget(project, "Unity-iPhone", ref.ID()); err != nil {
+		return fmt.Errorf("failed to remove Data from Unity-iPhone: %w", err)
+	}
+	if err := addDataToTarget(project, target, ref.ID()); err != nil {
+		return err
+	}
+
+	after, err := phaseMembership(project, ref.ID())
+	if err != nil {
+		return err
+	}
+	log.Println("📋 Data after:", after)
+	return nil
+}
+
+// ensureDataFileReference returns Unity's folder reference to Data at the
+// top of the navigator, as pbxproj.EnsureFolderReference finds or makes it.
+func ensureDataFileReference(project *pbxproj.Project, path string) (*pbxproj.PBXFileReference, error) {
+	ref, changes, err := project.EnsureFolderReference("", path)
+	if err != nil {
+		return nil, err
+	}
+	log.Println("📁 File reference for Data:", ref.ID(), ref.SourceTree)
+	for _, change := range changes {
+		log.Println("🛠", change)
+	}
+	return ref, nil
+}
+
+// phaseMembership lists the target/phase pairs whose build phases contain
+// refID, or "none".
+func phaseMembership(project *pbxproj.Project, refID string) (string, error) {
+	targets, err := project.NativeTargets()
+	if err != nil {
+		return "", err
+	}
+	var members []string
+	for _, t := range targets {
+		phases, err := project.TargetBuildPhases(t)
+		if err != nil {
+			return "", err
+		}
+		for _, phase := range phases {
+			files, err := project.PhaseBuildFiles(phase)
+			if err != nil {
+				return "", err
+			}
+			for _, bf := range files {
+				if bf.FileRef == refID {
+					members = append(members, t.Name+"/"+phase.DisplayName())
+				}
+			}
+		}
+	}
+	if len(members) == 0 {
+		return "none", nil
+	}
+	return strings.Join(members, ", "), nil
+}
+
+func removeDataFromTarget(project *pbxproj.Project, targetName, dataRefID string) error {
+	target, err := project.TargetByName(targetName)
+	if errors.Is(err, pbxproj.ErrNotFound) {
+		log.Println("ℹ️ No target found named:", targetName)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	phases, err := project.TargetBuildPhasesOf(target, "PBXResourcesBuildPhase")
+	if err != nil {
+		return err
+	}
+	for _, phase := range phases {
+		for _, id := range project.RemoveFromPhase(phase, dataRefID) {
+			log.Printf("🗑 Removing Data from %s/%s: %s\n", targetName, phase.DisplayName(), id)
+		}
+	}
+	return nil
+}
+
+// addDataToTarget makes Data a member of exactly one resources phase of
+// target: the first one already listing it, else the first one there is.
+func addDataToTarget(project *pbxproj.Project, target *pbxproj.PBXNativeTarget, dataRefID string) error {
+	phases, err := project.TargetBuildPhasesOf(target, "PBXResourcesBuildPhase")
+	if err != nil {
+		return err
+	}
+	if len(phases) == 0 {
+		return fmt.Errorf("No PBXResourcesBuildPhase found for target: %s", target.Name)
+	}
+
+	var keep *pbxproj.BuildPhase
+	for _, phase := range phases {
+		files, err := project.PhaseBuildFiles(phase)
+		if err != nil {
+			return err
+		}
+		for _, buildFile := range files {
+			if buildFile.FileRef == dataRefID {
+				keep = phase
+				break
+			}
+		}
+		if keep != nil {
+			break
+		}
+	}
+	if keep == nil {
+		keep = phases[0]
+	}
+
+	for _, phase := range phases {
+		if phase == keep {
+			continue
+		}
+		for _, id := range project.RemoveFromPhase(phase, dataRefID) {
+			log.Printf("🗑 Removing duplicate Data from %s/%s: %s\n", target.Name, phase.DisplayName(), id)
+		}
+	}
+	if _, added, err := project.AddToPhase(keep, dataRefID, nil); err != nil {
+		return err
+	} else if !added {
+		log.Println("ℹ️ Data already present in UnityFramework")
+		return nil
+	}
+	log.Printf("✅ Added Data to %s/%s\n", target.Name, keep.DisplayName())
+	return nil
+}
+
+// defaultHeaderVisibility makes every native plugin header public, so the
+// host app can import them from UnityFramework.
+const defaultHeaderVisibility = "Libraries/Plugins/iOS/**/*.h=public"
+
+// headerRule gives the headers whose project path matches pattern a
+// visibility.
+type headerRule struct {
+	pattern    string
+	visibility string
+}
+
+// headerRules parses comma-separated pattern=visibility rules.
+func headerRules(spec string) ([]headerRule, error) {
+	var rules []headerRule
+	for _, rule := range strings.Split(spec, ",") {
+		if rule = strings.TrimSpace(rule); rule == "" {
+			continue
+		}
+		pattern, vis, ok := strings.Cut(rule, "=")
+		if !ok || strings.TrimSpace(pattern) == "" {
+			return nil, fmt.Errorf("rule %q is not pattern=visibility", rule)
+		}
+		visibility, err := pbxproj.ParseVisibility(strings.TrimSpace(vis))
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, headerRule{strings.TrimSpace(pattern), visibility})
+	}
+	return rules, nil
+}
+
+// isHeader reports whether p names a C, C++ or Objective-C header.
+func isHeader(p string) bool {
+	switch strings.ToLower(filepath.Ext(p)) {
+	case ".h", ".hh", ".hpp":
+		return true
+	}
+	return false
+}
+
+// updateHeaderVisibility gives every header matching a rule the visibility
+// of the last rule it matches, adding it to the Headers phase of target
+// when it is not there yet. Other ATTRIBUTES and settings of the build
+// file are kept. The Headers phase is only created for a matching header.
+func updateHeaderVisibility(project *pbxproj.Project, target *pbxproj.PBXNativeTarget, rules []headerRule) error {
+	if len(rules) == 0 {
+		log.Println("ℹ️ No header visibility rules")
+		return nil
+	}
+
+	// Unity projects hold tens of thousands of il2cpp references: filter on
+	// the extension before resolving anything.
+	var headers []*pbxproj.PBXFileReference
+	for _, id := range project.IDs() {
+		if ref, ok := project.Objects[id].(*pbxproj.PBXFileReference); ok && isHeader(ref.Path) {
+			headers = append(headers, ref)
+		}
+	}
+
+	phase, err := project.FindPhase(target, "headers")
+	if err != nil {
+		return err
+	}
+	resolver := project.PathResolver()
+	matched := make([]int, len(rules))
+	for _, ref := range headers {
+		refPath := resolver.ResolvedPath(ref)
+		visibility := ""
+		for i, rule := range rules {
+			if pbxproj.MatchPath(rule.pattern, refPath) {
+				visibility = rule.visibility
+				matched[i]++
+			}
+		}
+		if visibility == "" {
+			continue
+		}
+
+		if phase == nil {
+			if phase, err = project.EnsurePhase(target, "headers"); err != nil {
+				return err
+			}
+			log.Printf("➕ Created the Headers phase of %s\n", target.Name)
+		}
+		buildFile, added, err := project.AddToPhase(phase, ref.ID(), nil)
+		if err != nil {
+			return err
+		}
+		if added {
+			log.Printf("➕ Added %s to %s/%s\n", refPath, target.Name, phase.DisplayName())
+		}
+		if before := buildFile.Visibility(); before != visibility {
+			buildFile.SetVisibility(visibility)
+			log.Printf("🛠 %s: %s -> %s\n", refPath, before, visibility)
+		} else if !added {
+			log.Printf("ℹ️ %s is already %s\n", refPath, visibility)
+		}
+	}
+
+	for i, rule := range rules {
+		if matched[i] == 0 {
+			log.Printf("⚠️ No header matches %s=%s\n", rule.pattern, strings.ToLower(rule.visibility))
+		}
+	}
+	return nil
+}
+
+func patchShouldAutorotate(plan *changeset.Plan, filePath string) error {
+	log.Println("🛠 Patching shouldAutorotate in:", filePath)
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("Failed to read file: %w", err)
+	}
+
+	content := string(data)
+	start := strings.Index(content, "- (BOOL)shouldAutorotate")
+	if start == -1 {
+		return fmt.Errorf("Method not found: - (BOOL)shouldAutorotate")
+	}
+
+	end := strings.Index(content[start:], "}")
+	if end == -1 {
+		return fmt.Errorf("Could not find method end bracket")
+	}
+
+	block := content[start : start+end]
+	if !strings.Contains(block, "return YES;") {
+		return fmt.Errorf("return YES; not found inside shouldAutorotate")
+	}
+
+	updatedBlock := strings.Replace(block, "return YES;", "return NO;", 1)
+	content = content[:start] + updatedBlock + content[start+end:]
+
+	if err := plan.WriteFile(filePath, []byte(content), 0644, "shouldAutorotate: return YES; -> return NO;"); err != nil {
+		return fmt.Errorf("Failed to write back patched file: %w", err)
+	}
+
+	log.Println("✅ Patched shouldAutorotate successfully")
+	return nil
+}
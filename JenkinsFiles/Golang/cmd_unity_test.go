@@ -0,0 +1,204 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/pbxproj"
+)
+
+// unityProject is a trimmed Unity-iPhone project: Data in two resource
+// phases of Unity-iPhone and one of UnityFramework, plugin headers in and
+// out of the Headers phase, and a second Data folder inside a plugin.
+const unityProject = `// !$*UTF8*$!
+{
+	archiveVersion = 1;
+	classes = {
+	};
+	objectVersion = 56;
+	objects = {
+		B1 = {isa = PBXBuildFile; fileRef = F1; };
+		B2 = {isa = PBXBuildFile; fileRef = F1; };
+		B3 = {isa = PBXBuildFile; fileRef = F1; };
+		B4 = {isa = PBXBuildFile; fileRef = H1; settings = {ATTRIBUTES = (Weak, ); COMPILER_FLAGS = "-x"; }; };
+		B5 = {isa = PBXBuildFile; fileRef = H2; };
+		F1 = {isa = PBXFileReference; explicitFileType = folder; path = Data; sourceTree = SOURCE_ROOT; };
+		F2 = {isa = PBXFileReference; lastKnownFileType = folder; path = Data; sourceTree = "<group>"; };
+		H1 = {isa = PBXFileReference; lastKnownFileType = sourcecode.c.h; path = A.h; sourceTree = "<group>"; };
+		H2 = {isa = PBXFileReference; lastKnownFileType = sourcecode.c.h; path = B.h; sourceTree = "<group>"; };
+		H3 = {isa = PBXFileReference; lastKnownFileType = sourcecode.c.h; path = C.h; sourceTree = "<group>"; };
+		G0 = {isa = PBXGroup; children = (F1, G1, ); sourceTree = "<group>"; };
+		G1 = {isa = PBXGroup; children = (G2, ); path = Libraries; sourceTree = "<group>"; };
+		G2 = {isa = PBXGroup; children = (G3, ); path = Plugins; sourceTree = "<group>"; };
+		G3 = {isa = PBXGroup; children = (H1, H2, G4, ); path = iOS; sourceTree = "<group>"; };
+		G4 = {isa = PBXGroup; children = (H3, F2, ); path = Sub; sourceTree = "<group>"; };
+		R1 = {isa = PBXResourcesBuildPhase; buildActionMask = 2147483647; files = (B1, ); runOnlyForDeploymentPostprocessing = 0; };
+		R2 = {isa = PBXResourcesBuildPhase; buildActionMask = 2147483647; files = (B2, ); name = More; runOnlyForDeploymentPostprocessing = 0; };
+		R3 = {isa = PBXResourcesBuildPhase; buildActionMask = 2147483647; files = ( ); runOnlyForDeploymentPostprocessing = 0; };
+		R4 = {isa = PBXResourcesBuildPhase; buildActionMask = 2147483647; files = (B3, ); name = Extra; runOnlyForDeploymentPostprocessing = 0; };
+		P1 = {isa = PBXHeadersBuildPhase; buildActionMask = 2147483647; files = (B4, B5, ); runOnlyForDeploymentPostprocessing = 0; };
+		T1 = {isa = PBXNativeTarget; buildConfigurationList = L1; buildPhases = (R1, R2, ); name = "Unity-iPhone"; productName = "Unity-iPhone"; productType = "com.apple.product-type.application"; };
+		T2 = {isa = PBXNativeTarget; buildConfigurationList = L2; buildPhases = (P1, R3, R4, ); name = UnityFramework; productName = UnityFramework; productType = "com.apple.product-type.framework"; };
+		C1 = {isa = XCBuildConfiguration; buildSettings = {}; name = Release; };
+		C2 = {isa = XCBuildConfiguration; buildSettings = {}; name = Release; };
+		C3 = {isa = XCBuildConfiguration; buildSettings = {}; name = Release; };
+		L1 = {isa = XCConfigurationList; buildConfigurations = (C1, ); };
+		L2 = {isa = XCConfigurationList; buildConfigurations = (C2, ); };
+		L3 = {isa = XCConfigurationList; buildConfigurations = (C3, ); };
+		PR = {isa = PBXProject; buildConfigurationList = L3; mainGroup = G0; targets = (T1, T2, ); };
+	};
+	rootObject = PR;
+}
+`
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func parseUnityProject(t *testing.T) (*pbxproj.Project, *pbxproj.PBXNativeTarget) {
+	t.Helper()
+	project, err := pbxproj.Parse([]byte(unityProject), "Unity-iPhone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := project.TargetByName("UnityFramework")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return project, target
+}
+
+func headerVisibilities(t *testing.T, project *pbxproj.Project, phase *pbxproj.BuildPhase) map[string]string {
+	t.Helper()
+	files, err := project.PhaseBuildFiles(phase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, bf := range files {
+		got[bf.FileRef] = bf.Visibility()
+	}
+	return got
+}
+
+func TestUpdateHeaderVisibility(t *testing.T) {
+	project, target := parseUnityProject(t)
+	rules, err := headerRules("Libraries/Plugins/iOS/**/*.h=public, Libraries/Plugins/iOS/Sub/*.h=private")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := updateHeaderVisibility(project, target, rules); err != nil {
+		t.Fatal(err)
+	}
+
+	phase, _ := project.FindPhase(target, "headers")
+	want := map[string]string{"H1": pbxproj.VisibilityPublic, "H2": pbxproj.VisibilityPublic, "H3": pbxproj.VisibilityPrivate}
+	if got := headerVisibilities(t, project, phase); !reflect.DeepEqual(got, want) {
+		t.Errorf("visibilities = %v, want %v", got, want)
+	}
+	// Other attributes and settings survive.
+	b4, _ := project.BuildFile("B4")
+	if got := b4.Settings["ATTRIBUTES"]; !reflect.DeepEqual(got, []interface{}{"Weak", "Public"}) {
+		t.Errorf("B4 ATTRIBUTES = %v", got)
+	}
+	if b4.Settings["COMPILER_FLAGS"] != "-x" {
+		t.Errorf("B4 lost COMPILER_FLAGS: %v", b4.Settings)
+	}
+}
+
+func TestUpdateHeaderVisibilityCreatesPhaseOnlyWhenNeeded(t *testing.T) {
+	project, target := parseUnityProject(t)
+	target.BuildPhases = []string{"R3", "R4"}
+
+	rules, _ := headerRules("Nothing/*.h=public")
+	if err := updateHeaderVisibility(project, target, rules); err != nil {
+		t.Fatal(err)
+	}
+	if phase, _ := project.FindPhase(target, "headers"); phase != nil {
+		t.Fatalf("Headers phase created without a matching header")
+	}
+
+	rules, _ = headerRules(defaultHeaderVisibility)
+	if err := updateHeaderVisibility(project, target, rules); err != nil {
+		t.Fatal(err)
+	}
+	phase, _ := project.FindPhase(target, "headers")
+	if phase == nil || len(phase.Files) != 3 {
+		t.Fatalf("Headers phase = %+v, want the three plugin headers", phase)
+	}
+}
+
+func TestHeaderRules(t *testing.T) {
+	rules, err := headerRules(" a/*.h=Public ,, b/**/*.h=project ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []headerRule{{"a/*.h", pbxproj.VisibilityPublic}, {"b/**/*.h", pbxproj.VisibilityProject}}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("rules = %v, want %v", rules, want)
+	}
+	for _, bad := range []string{"a/*.h", "=public", "a/*.h=loud"} {
+		if _, err := headerRules(bad); err == nil {
+			t.Errorf("headerRules(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestMoveDataToFramework(t *testing.T) {
+	project, target := parseUnityProject(t)
+	sourceRoot := t.TempDir()
+	if err := moveDataToFramework(project, sourceRoot, "Data", target); err == nil {
+		t.Fatal("moved Data although the folder does not exist")
+	}
+	if err := os.Mkdir(filepath.Join(sourceRoot, "Data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := moveDataToFramework(project, sourceRoot, "Data", target); err != nil {
+		t.Fatal(err)
+	}
+
+	f1, _ := project.FileReference("F1")
+	if f1.LastKnownFileType != "folder" || f1.ExplicitFileType != "" {
+		t.Errorf("Data = %+v, want lastKnownFileType folder only", f1)
+	}
+	if got, _ := phaseMembership(project, "F1"); got != "UnityFramework/Extra" {
+		t.Errorf("Data is in %s, want UnityFramework/Extra only", got)
+	}
+	// The plugin's own Data folder is another folder.
+	if got, _ := phaseMembership(project, "F2"); got != "none" {
+		t.Errorf("plugin Data is in %s", got)
+	}
+}
+
+func TestMoveDataToFrameworkCreatesReference(t *testing.T) {
+	project, target := parseUnityProject(t)
+	main, _ := project.MainGroup()
+	main.Children = []string{"G1"}
+	for _, id := range []string{"B1", "B2", "B3", "F1"} {
+		project.Remove(id)
+	}
+	for _, id := range []string{"R1", "R2", "R4"} {
+		phase, _ := project.BuildPhase(id)
+		phase.Files = []string{}
+	}
+	sourceRoot := t.TempDir()
+	if err := os.Mkdir(filepath.Join(sourceRoot, "Data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := moveDataToFramework(project, sourceRoot, "Data", target); err != nil {
+		t.Fatal(err)
+	}
+	ref := project.Objects[main.Children[1]].(*pbxproj.PBXFileReference)
+	if ref.Path != "Data" || ref.SourceTree != pbxproj.SourceTreeSourceRoot || ref.LastKnownFileType != "folder" {
+		t.Errorf("new Data reference = %+v", ref)
+	}
+	if got, _ := phaseMembership(project, ref.ID()); got != "UnityFramework/Resources" {
+		t.Errorf("Data is in %s, want UnityFramework/Resources", got)
+	}
+}
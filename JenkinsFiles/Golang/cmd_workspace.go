@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/changeset"
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/cocos"
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/config"
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/xcworkspace"
+)
+
+var workspaceAddCmd = &command{
+	group:   "workspace",
+	name:    "add",
+	summary: "Add the Cocos Xcode project to the Xcode workspace",
+}
+
+var workspaceNormalizeCmd = &command{
+	group:   "workspace",
+	name:    "normalize",
+	summary: "Rewrite absolute project locations in a workspace as relative ones",
+}
+
+func init() {
+	workspaceAddCmd.run = runWorkspaceAdd
+	workspaceNormalizeCmd.run = runWorkspaceNormalize
+	register(workspaceAddCmd)
+	register(workspaceNormalizeCmd)
+}
+
+func runWorkspaceAdd(args []string) error {
+	fs := newFlagSet(workspaceAddCmd, "")
+	flags := bindPlanFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	cfg, plan, err := flags.open(workspaceAddCmd, config.Config{
+		BaseDir:      executableDir(),
+		CocosProject: "cocosProject",
+		WorkspaceDir: "XcodeWorkspace",
+	})
+	if err != nil {
+		return err
+	}
+	defer plan.Close()
+	defer plan.WriteText(os.Stdout)
+
+	var project *cocos.Project
+	if cfg.CocosXcodeDir == "" || cfg.Product == "" {
+		if project, err = openCocosProject(cfg); err != nil {
+			return err
+		}
+	}
+	product := cocosProduct(cfg, project)
+	xcodeProjPath, err := findCocosXcodeProj(cfg, project, product)
+	if err != nil {
+		return err
+	}
+	return addToWorkspace(plan, cfg, product, xcodeProjPath)
+}
+
+// cocosProduct is the configured product name, else the one the Cocos
+// build names its Xcode project after. project may be nil.
+func cocosProduct(cfg *config.Config, project *cocos.Project) string {
+	if cfg.Product != "" || project == nil {
+		return cfg.Product
+	}
+	return project.ProductName()
+}
+
+// findCocosXcodeProj picks the .xcodeproj the Cocos build generated, in the
+// folder its Creator version uses unless configured otherwise. project may
+// be nil when CocosXcodeDir is set.
+func findCocosXcodeProj(cfg *config.Config, project *cocos.Project, product string) (string, error) {
+	dir := cfg.CocosXcodeDir
+	if dir == "" {
+		dir = project.XcodeDir()
+	}
+	return discoverXcodeProj(os.Stdout, "Cocos Xcode project", dir, cfg.CocosXcodeProj, product)
+}
+
+// addToWorkspace adds xcodeProjPath to the configured workspace, with a
+// location relative to the workspace.
+func addToWorkspace(plan *changeset.Plan, cfg *config.Config, product, xcodeProjPath string) error {
+	wsPath, err := findWorkspace(cfg, product)
+	if err != nil {
+		return err
+	}
+	ws, err := xcworkspace.Load(wsPath)
+	if err != nil {
+		return err
+	}
+
+	absXcodeProjPath, err := filepath.Abs(xcodeProjPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	ref, added, err := ws.Add(nil, absXcodeProjPath)
+	if err != nil {
+		return err
+	}
+	if added {
+		if err := saveWorkspace(plan, ws, "+ FileRef "+ref.Location.String()); err != nil {
+			return err
+		}
+		fmt.Println("✅ Xcode project added to workspace:", ref.Location)
+		return nil
+	}
+
+	// Present, but maybe with a path that breaks when the folder moves.
+	before := ref.Location
+	changed, err := ws.Relativize(ref)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		fmt.Println("ℹ️ Xcode project already present in workspace:", before)
+		return nil
+	}
+	if err := saveWorkspace(plan, ws, "~ FileRef "+before.String()+" -> "+ref.Location.String()); err != nil {
+		return err
+	}
+	fmt.Println("✅ Xcode project location made relative:", ref.Location)
+	return nil
+}
+
+func runWorkspaceNormalize(args []string) error {
+	fs := newFlagSet(workspaceNormalizeCmd, "[Workspace.xcworkspace]")
+	flags := bindPlanFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return usageErrorf("at most one workspace can be normalized")
+	}
+	cfg, plan, err := flags.open(workspaceNormalizeCmd, config.Config{
+		BaseDir:      executableDir(),
+		WorkspaceDir: "XcodeWorkspace",
+	})
+	if err != nil {
+		return err
+	}
+	defer plan.Close()
+	defer plan.WriteText(os.Stdout)
+
+	var wsPath string
+	if fs.NArg() == 1 {
+		wsPath = cfg.Resolve(fs.Arg(0))
+	} else if wsPath, err = findWorkspace(cfg, cfg.Product); err != nil {
+		return err
+	}
+	ws, err := xcworkspace.Load(wsPath)
+	if err != nil {
+		return err
+	}
+
+	var details []string
+	for _, ref := range ws.Duplicates() {
+		ws.RemoveRef(ref)
+		details = append(details, "- FileRef "+ref.Location.String()+" (duplicate)")
+		fmt.Printf("✅ Removed duplicate reference %s to %s\n", ref.Location, ref.Path)
+	}
+
+	refs, err := ws.Refs()
+	if err != nil {
+		fmt.Println("⚠️", err)
+	}
+	for _, ref := range refs {
+		if ref.Location.Kind != xcworkspace.KindAbsolute {
+			continue
+		}
+		if _, err := os.Stat(ref.Path); err != nil {
+			fmt.Println("⚠️ Referenced project does not exist:", ref.Path)
+		}
+		before := ref.Location
+		if _, err := ws.Relativize(ref); err != nil {
+			return err
+		}
+		details = append(details, "~ FileRef "+before.String()+" -> "+ref.Location.String())
+		fmt.Printf("✅ %s -> %s\n", before, ref.Location)
+	}
+	if len(details) == 0 {
+		fmt.Println("ℹ️ All workspace locations are already relative and unique.")
+		return nil
+	}
+	return saveWorkspace(plan, ws, details...)
+}
+
+// findWorkspace picks the configured .xcworkspace under WorkspaceDir, or
+// the one named after product when there are several, and reports it.
+func findWorkspace(cfg *config.Config, product string) (string, error) {
+	d, err := config.DiscoverWorkspace(cfg.WorkspaceDir, cfg.Workspace, product)
+	if err != nil {
+		return "", fmt.Errorf("Xcode workspace: %w", err)
+	}
+	reportDiscovery(os.Stdout, "Xcode workspace", d)
+	return d.Path, nil
+}
+
+func saveWorkspace(plan *changeset.Plan, ws *xcworkspace.Workspace, details ...string) error {
+	if err := plan.WriteFile(ws.ContentsPath(), ws.Marshal(), 0644, details...); err != nil {
+		return fmt.Errorf("failed to write workspace file: %w", err)
+	}
+	return nil
+}
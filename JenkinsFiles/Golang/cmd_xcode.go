@@ -0,0 +1,328 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/pbxproj"
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/recipe"
+)
+
+var xcodeValidateCmd = &command{
+	group:   "xcode",
+	name:    "validate",
+	summary: "Check project.pbxproj files for dangling references, duplicates and orphans",
+}
+
+var xcodeDiffCmd = &command{
+	group:   "xcode",
+	name:    "diff",
+	summary: "Compare two Xcode projects by target, phase, file and build setting",
+}
+
+var xcodeApplyRecipeCmd = &command{
+	group:   "xcode",
+	name:    "apply-recipe",
+	summary: "Apply a YAML or JSON patch recipe to an Xcode project",
+}
+
+var xcodeSettingsCmd = &command{
+	group:   "xcode",
+	name:    "settings",
+	summary: "Get, set, append to or remove build settings",
+}
+
+func init() {
+	xcodeValidateCmd.run = runXcodeValidate
+	xcodeDiffCmd.run = runXcodeDiff
+	xcodeApplyRecipeCmd.run = runXcodeApplyRecipe
+	xcodeSettingsCmd.run = runXcodeSettings
+	register(xcodeValidateCmd)
+	register(xcodeDiffCmd)
+	register(xcodeApplyRecipeCmd)
+	register(xcodeSettingsCmd)
+}
+
+func runXcodeValidate(args []string) error {
+	fs := newFlagSet(xcodeValidateCmd, "<project.pbxproj | Project.xcodeproj>...")
+	strict := fs.Bool("strict", false, "treat warnings (orphans, unknown isa) as errors")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return usageErrorf("no project given")
+	}
+
+	code := exitOK
+	for _, arg := range fs.Args() {
+		path := pbxprojPath(arg)
+		project, err := pbxproj.Load(path)
+		if err != nil {
+			fmt.Printf("❌ Failed to load %s: %v\n", path, err)
+			code = exitFailed
+			continue
+		}
+
+		errors, warnings := 0, 0
+		for _, issue := range project.Validate() {
+			if issue.Severity == pbxproj.Error {
+				errors++
+				fmt.Println("❌", issue)
+			} else {
+				warnings++
+				fmt.Println("⚠️", issue)
+			}
+		}
+
+		switch {
+		case errors > 0 || (*strict && warnings > 0):
+			fmt.Printf("❌ %s: %d error(s), %d warning(s)\n", path, errors, warnings)
+			if code != exitFailed {
+				code = exitFindings
+			}
+		case warnings > 0:
+			fmt.Printf("⚠️ %s: %d warning(s)\n", path, warnings)
+			if code == exitOK {
+				code = exitWarnings
+			}
+		default:
+			fmt.Printf("✅ %s: no issues found\n", path)
+		}
+	}
+	if code != exitOK {
+		return exitCode(code)
+	}
+	return nil
+}
+
+func runXcodeDiff(args []string) error {
+	fs := newFlagSet(xcodeDiffCmd, "<before> <after>")
+	asJSON := fs.Bool("json", false, "print the diff as JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return usageErrorf("need exactly two projects")
+	}
+
+	before, err := loadPbxproj(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	after, err := loadPbxproj(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	diff, err := pbxproj.Diff(before, after)
+	if err != nil {
+		return fmt.Errorf("failed to compare projects: %w", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(diff); err != nil {
+			return fmt.Errorf("failed to encode diff: %w", err)
+		}
+	} else {
+		diff.WriteText(os.Stdout)
+	}
+	if !diff.Empty() {
+		return exitCode(exitFindings)
+	}
+	return nil
+}
+
+func runXcodeApplyRecipe(args []string) error {
+	fs := newFlagSet(xcodeApplyRecipeCmd, "<recipe.yaml|recipe.json> <project.pbxproj | Project.xcodeproj>")
+	dryRun := fs.Bool("dry-run", false, "print the change plan without writing anything")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return usageErrorf("need a recipe and a project")
+	}
+
+	r, err := recipe.Load(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid recipe: %w", err)
+	}
+	path := pbxprojPath(fs.Arg(1))
+	project, err := loadPbxproj(path)
+	if err != nil {
+		return err
+	}
+	original, err := project.Clone()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot pbxproj: %w", err)
+	}
+
+	fmt.Printf("📜 Applying recipe %q (%d operations) to %s\n", r.Name, len(r.Operations), path)
+	changes, err := recipe.Apply(project, r)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Println("ℹ️ Project already up to date, nothing written.")
+		return nil
+	}
+	for _, c := range changes {
+		fmt.Println("✅", c)
+	}
+
+	plan, err := openPlan(xcodeApplyRecipeCmd, *dryRun)
+	if err != nil {
+		return err
+	}
+	defer plan.Close()
+	if err := plan.SaveProject(path, project, original); err != nil {
+		return fmt.Errorf("failed to write pbxproj: %w", err)
+	}
+	plan.WriteText(os.Stdout)
+	if !plan.DryRun {
+		fmt.Println("🎉 Recipe applied successfully.")
+	}
+	return nil
+}
+
+func runXcodeSettings(args []string) error {
+	fs := newFlagSet(xcodeSettingsCmd, "<project.pbxproj | Project.xcodeproj> get|set|append|remove KEY [VALUE...]")
+	dryRun := fs.Bool("dry-run", false, "print the change plan without writing anything")
+	targetName := fs.String("target", "", "native target to edit (default: project-level settings)")
+	configName := fs.String("configuration", pbxproj.AllConfigurations, "configuration to edit: Debug, Release, ... or all")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s xcode settings [flags] <project.pbxproj | Project.xcodeproj> <action> KEY [VALUE...]\n\n", toolName)
+		fmt.Fprintln(fs.Output(), "actions:")
+		fmt.Fprintln(fs.Output(), "  get KEY               print the value in each configuration")
+		fmt.Fprintln(fs.Output(), "  set KEY VALUE...      set a value; several values store a list")
+		fmt.Fprintln(fs.Output(), "  append KEY VALUE...   add values to a list, keeping $(inherited)")
+		fmt.Fprintln(fs.Output(), "  remove KEY [VALUE...] remove values from a list, or the whole setting")
+		fmt.Fprintln(fs.Output(), "\nflags:")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() < 3 {
+		return usageErrorf("need a project, an action and a key")
+	}
+	action, key, values := fs.Arg(1), fs.Arg(2), fs.Args()[3:]
+	switch action {
+	case "get", "remove":
+	case "set", "append":
+		if len(values) == 0 {
+			return usageErrorf("%s needs at least one value", action)
+		}
+	default:
+		return usageErrorf("unknown action %q", action)
+	}
+
+	path := pbxprojPath(fs.Arg(0))
+	project, err := loadPbxproj(path)
+	if err != nil {
+		return err
+	}
+	original, err := project.Clone()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot pbxproj: %w", err)
+	}
+
+	var target *pbxproj.PBXNativeTarget
+	scope := "project"
+	if *targetName != "" {
+		if target, err = project.TargetByName(*targetName); err != nil {
+			return err
+		}
+		scope = target.Name
+	}
+	configs, err := project.Configurations(target, *configName)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	for _, c := range configs {
+		where := scope + "/" + c.Name
+		switch action {
+		case "get":
+			if v, ok := c.Get(key); ok {
+				fmt.Printf("%s: %s = %s\n", where, key, formatSetting(v))
+			} else {
+				fmt.Printf("%s: %s is not set\n", where, key)
+			}
+		case "set":
+			var value interface{} = values[0]
+			if len(values) > 1 {
+				list := make([]interface{}, len(values))
+				for i, v := range values {
+					list[i] = v
+				}
+				value = list
+			}
+			if old, ok := c.Get(key); ok && formatSetting(old) == formatSetting(value) {
+				fmt.Printf("ℹ️ %s: %s already %s\n", where, key, formatSetting(value))
+				continue
+			}
+			c.Set(key, value)
+			fmt.Printf("✅ %s: %s = %s\n", where, key, formatSetting(value))
+			changed = true
+		case "append":
+			if !c.Append(key, values...) {
+				fmt.Printf("ℹ️ %s: %s already contains %s\n", where, key, strings.Join(values, " "))
+				continue
+			}
+			v, _ := c.Get(key)
+			fmt.Printf("✅ %s: %s = %s\n", where, key, formatSetting(v))
+			changed = true
+		case "remove":
+			var removed bool
+			if len(values) == 0 {
+				removed = c.Delete(key)
+			} else {
+				removed = c.RemoveValues(key, values...)
+			}
+			if !removed {
+				fmt.Printf("ℹ️ %s: nothing to remove from %s\n", where, key)
+				continue
+			}
+			if v, ok := c.Get(key); ok {
+				fmt.Printf("✅ %s: %s = %s\n", where, key, formatSetting(v))
+			} else {
+				fmt.Printf("✅ %s: removed %s\n", where, key)
+			}
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	plan, err := openPlan(xcodeSettingsCmd, *dryRun)
+	if err != nil {
+		return err
+	}
+	defer plan.Close()
+	if err := plan.SaveProject(path, project, original); err != nil {
+		return fmt.Errorf("failed to write pbxproj: %w", err)
+	}
+	plan.WriteText(os.Stdout)
+	if !plan.DryRun {
+		fmt.Println("🎉 Build settings updated.")
+	}
+	return nil
+}
+
+// formatSetting renders a setting value the way Xcode's build settings
+// editor shows it: lists as space-separated entries.
+func formatSetting(v interface{}) string {
+	if list, ok := v.([]interface{}); ok {
+		parts := make([]string, len(list))
+		for i, item := range list {
+			parts[i] = fmt.Sprint(item)
+		}
+		return "(" + strings.Join(parts, " ") + ")"
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestXcodeValidateExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, text string) string {
+		path := filepath.Join(dir, name+".pbxproj")
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	withObject := func(object string) string {
+		return strings.Replace(cocosProject, "\t\tB1 = ", "\t\t"+object+"\n\t\tB1 = ", 1)
+	}
+	clean := write("clean", cocosProject)
+	orphan := write("orphan", withObject(`F9 = {isa = PBXFileReference; path = Stray.swift; sourceTree = "<group>"; };`))
+	dangling := write("dangling", strings.Replace(cocosProject, "files = (B1, );", "files = (B1, B9, );", 1))
+	missing := filepath.Join(dir, "missing.pbxproj")
+
+	// The report goes to stdout; keep it out of the test output.
+	stdout := os.Stdout
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{clean}, exitOK},
+		{[]string{"-strict", clean}, exitOK},
+		{[]string{orphan}, exitWarnings},
+		{[]string{"-strict", orphan}, exitFindings},
+		{[]string{dangling}, exitFindings},
+		{[]string{orphan, dangling}, exitFindings},
+		{[]string{dangling, missing}, exitFailed},
+		{[]string{}, exitUsage},
+	}
+	for _, tt := range tests {
+		if got := runCommand(xcodeValidateCmd, tt.args); got != tt.want {
+			t.Errorf("xcode validate %s = %d, want %d", strings.Join(tt.args, " "), got, tt.want)
+		}
+	}
+}
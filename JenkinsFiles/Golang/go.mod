@@ -0,0 +1,8 @@
+module github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang
+
+go 1.21
+
+require (
+	gopkg.in/yaml.v3 v3.0.1
+	howett.net/plist v1.0.1
+)
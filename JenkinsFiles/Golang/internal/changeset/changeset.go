@@ -0,0 +1,266 @@
+// Package changeset routes the filesystem mutations of the build tools
+// through a Plan, so a run can either apply them or, with -dry-run, only
+// print what it would have done. Applied plans write atomically and keep a
+// Journal of backups that the rollback tool can restore.
+package changeset
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/atomicfile"
+)
+
+// Kinds of Action.
+const (
+	KindWrite  = "write"
+	KindRemove = "remove"
+	KindCopy   = "copy"
+	KindRun    = "run"
+)
+
+// Action is one recorded mutation.
+type Action struct {
+	Kind string
+	// Path is the file or folder affected; for KindRun it describes the
+	// command.
+	Path string
+	// Source is the folder copied from, for KindCopy.
+	Source string
+	// Details are extra lines shown under the action, such as the pbxproj
+	// objects a write creates or deletes.
+	Details []string
+}
+
+// Plan records actions and, unless DryRun is set, performs them as they
+// are recorded. Reads are never affected, so code can run unchanged in
+// both modes.
+type Plan struct {
+	DryRun bool
+	// Journal, when set, backs up every path before it is changed.
+	Journal *Journal
+	actions []Action
+}
+
+// New returns an empty plan without a journal.
+func New(dryRun bool) *Plan {
+	return &Plan{DryRun: dryRun}
+}
+
+// Open returns an empty plan for a run of tool. Unless this is a dry run,
+// the plan keeps a journal under JournalRoot.
+func Open(tool string, dryRun bool) (*Plan, error) {
+	p := New(dryRun)
+	if dryRun {
+		return p, nil
+	}
+	j, err := NewJournal(JournalRoot(), tool)
+	if err != nil {
+		return nil, fmt.Errorf("creating backup journal: %w", err)
+	}
+	p.Journal = j
+	// Old journals are only a convenience; failing to prune them must not
+	// fail the run.
+	PruneJournals(JournalRoot(), journalKeep())
+	return p, nil
+}
+
+// Close finishes the run: a journal with nothing in it is deleted. A run
+// that dies before Close keeps its journal for rollback.
+func (p *Plan) Close() error {
+	if p.Journal == nil || len(p.Journal.Entries) > 0 {
+		return nil
+	}
+	return os.RemoveAll(p.Journal.Dir)
+}
+
+// Actions returns the actions recorded so far, in order.
+func (p *Plan) Actions() []Action {
+	return p.actions
+}
+
+// WriteFile atomically writes data to path. Writing the content a file
+// already has is not recorded. details are shown under the action in the
+// plan.
+func (p *Plan) WriteFile(path string, data []byte, perm os.FileMode, details ...string) error {
+	if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, data) {
+		return nil
+	}
+	p.actions = append(p.actions, Action{Kind: KindWrite, Path: path, Details: details})
+	if p.DryRun {
+		return nil
+	}
+	if p.Journal != nil {
+		if err := p.Journal.beforeChange(path); err != nil {
+			return err
+		}
+	}
+	return atomicfile.WriteFile(path, data, perm)
+}
+
+// RemoveAll deletes path and everything below it. A path that does not
+// exist is not recorded.
+func (p *Plan) RemoveAll(path string) error {
+	if _, err := os.Lstat(path); os.IsNotExist(err) {
+		return nil
+	}
+	p.actions = append(p.actions, Action{Kind: KindRemove, Path: path})
+	if p.DryRun {
+		return nil
+	}
+	if p.Journal != nil {
+		return p.Journal.remove(path)
+	}
+	return os.RemoveAll(path)
+}
+
+// Discard deletes path and everything below it without backing it up, for
+// output and caches the build regenerates, which can be gigabytes. A path
+// that does not exist is not recorded.
+func (p *Plan) Discard(path string) error {
+	if _, err := os.Lstat(path); os.IsNotExist(err) {
+		return nil
+	}
+	p.actions = append(p.actions, Action{Kind: KindRemove, Path: path, Details: []string{"not backed up: regenerated by the build"}})
+	if p.DryRun {
+		return nil
+	}
+	return os.RemoveAll(path)
+}
+
+// CopyDir copies the folder src to dst recursively, creating dst as needed.
+func (p *Plan) CopyDir(src, dst string) error {
+	files, err := listFiles(src)
+	if err != nil {
+		return err
+	}
+	p.actions = append(p.actions, Action{Kind: KindCopy, Path: dst, Source: src, Details: files})
+	if p.DryRun {
+		return nil
+	}
+	if p.Journal != nil {
+		if err := p.Journal.beforeChange(dst); err != nil {
+			return err
+		}
+	}
+	return copyDir(src, dst)
+}
+
+// Run records an external step, such as launching a build, and calls fn
+// unless this is a dry run.
+func (p *Plan) Run(description string, fn func() error) error {
+	p.actions = append(p.actions, Action{Kind: KindRun, Path: description})
+	if p.DryRun {
+		return nil
+	}
+	return fn()
+}
+
+// WriteText prints the plan for humans.
+func (p *Plan) WriteText(w io.Writer) {
+	if len(p.actions) == 0 {
+		fmt.Fprintln(w, "ℹ️ Nothing to change.")
+		return
+	}
+	if p.DryRun {
+		fmt.Fprintf(w, "📝 Change plan (dry run, %d action(s), nothing written):\n", len(p.actions))
+	} else {
+		fmt.Fprintf(w, "📝 Applied %d action(s):\n", len(p.actions))
+	}
+	for _, a := range p.actions {
+		if a.Kind == KindCopy {
+			// The file list is only useful for counting.
+			fmt.Fprintf(w, "  %-6s %s -> %s (%d file(s))\n", a.Kind, a.Source, a.Path, len(a.Details))
+			continue
+		}
+		fmt.Fprintf(w, "  %-6s %s\n", a.Kind, a.Path)
+		for _, d := range a.Details {
+			fmt.Fprintf(w, "           %s\n", d)
+		}
+	}
+	if p.Journal != nil && len(p.Journal.Entries) > 0 {
+		fmt.Fprintln(w, "💾 Backup journal:", p.Journal.Dir)
+	}
+}
+
+// listFiles returns the files below dir relative to it.
+func listFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	return files, err
+}
+
+func copyDir(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		srcPath := filepath.Join(src, entry.Name())
+		dstPath := filepath.Join(dst, entry.Name())
+		switch {
+		case entry.Type()&fs.ModeSymlink != 0:
+			err = copySymlink(srcPath, dstPath)
+		case entry.IsDir():
+			err = copyDir(srcPath, dstPath)
+		default:
+			err = copyFile(srcPath, dstPath)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	return out.Sync()
+}
+
+// copySymlink recreates the link src at dst, pointing at the same target,
+// as frameworks and Creator's library/ rely on relative links.
+func copySymlink(src, dst string) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Symlink(target, dst)
+}
@@ -0,0 +1,140 @@
+package changeset
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestCopyDirKeepsSymlinks(t *testing.T) {
+	// The layout of a framework bundle.
+	src := writeTree(t, map[string]string{"Versions/A/Headers/Unity.h": "header", "Versions/A/Unity": "binary"})
+	for link, target := range map[string]string{
+		"Versions/Current": "A",
+		"Headers":          "Versions/Current/Headers",
+		"Unity":            "Versions/Current/Unity",
+		"Dangling":         "missing",
+	} {
+		if err := os.Symlink(target, filepath.Join(src, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dst := filepath.Join(t.TempDir(), "Copy.framework")
+	if err := New(false).CopyDir(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	for link, want := range map[string]string{"Versions/Current": "A", "Headers": "Versions/Current/Headers", "Unity": "Versions/Current/Unity", "Dangling": "missing"} {
+		got, err := os.Readlink(filepath.Join(dst, link))
+		if err != nil {
+			t.Errorf("%s: %v", link, err)
+		} else if got != want {
+			t.Errorf("%s -> %s, want %s", link, got, want)
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(dst, "Headers", "Unity.h")); err != nil || string(data) != "header" {
+		t.Errorf("Headers/Unity.h through the links = %q, %v", data, err)
+	}
+}
+
+func TestDiscardSkipsJournal(t *testing.T) {
+	dir := writeTree(t, map[string]string{"library/big.bin": "cache", "settings.json": "{}"})
+	j, err := NewJournal(t.TempDir(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Plan{Journal: j}
+	if err := p.Discard(filepath.Join(dir, "library")); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.RemoveAll(filepath.Join(dir, "settings.json")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "library")); !os.IsNotExist(err) {
+		t.Errorf("library still exists: %v", err)
+	}
+	if len(j.Entries) != 1 || filepath.Base(j.Entries[0].Path) != "settings.json" {
+		t.Fatalf("journal entries = %+v, want only settings.json", j.Entries)
+	}
+	if len(p.Actions()) != 2 {
+		t.Errorf("actions = %+v, want both removals", p.Actions())
+	}
+
+	if _, err := j.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "settings.json")); err != nil || string(data) != "{}" {
+		t.Errorf("settings.json after rollback = %q, %v", data, err)
+	}
+}
+
+func TestRollbackRestoresSymlinks(t *testing.T) {
+	dir := writeTree(t, map[string]string{"set/A": "a"})
+	if err := os.Symlink("A", filepath.Join(dir, "set", "B")); err != nil {
+		t.Fatal(err)
+	}
+	j, err := NewJournal(t.TempDir(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := (&Plan{Journal: j}).RemoveAll(filepath.Join(dir, "set")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if target, err := os.Readlink(filepath.Join(dir, "set", "B")); err != nil || target != "A" {
+		t.Errorf("set/B after rollback -> %q, %v; want a link to A", target, err)
+	}
+}
+
+func TestPruneJournals(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"20240101-000000-a-1", "20240102-000000-b-1", "20240103-000000-c-1", "20240104-000000-d-1"} {
+		j := &Journal{Dir: filepath.Join(root, name), Tool: name}
+		if err := os.Mkdir(j.Dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := j.save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Not a journal: left alone and not counted.
+	if err := os.Mkdir(filepath.Join(root, "00000000-other"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := PruneJournals(root, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 2 || filepath.Base(removed[0]) != "20240101-000000-a-1" || filepath.Base(removed[1]) != "20240102-000000-b-1" {
+		t.Errorf("removed %v, want the two oldest", removed)
+	}
+	left, _ := Journals(root)
+	if len(left) != 2 || filepath.Base(left[0]) != "20240103-000000-c-1" {
+		t.Errorf("left %v", left)
+	}
+	if _, err := os.Stat(filepath.Join(root, "00000000-other")); err != nil {
+		t.Errorf("non-journal folder removed: %v", err)
+	}
+
+	if removed, _ := PruneJournals(root, 0); len(removed) != 0 {
+		t.Errorf("keep 0 removed %v, want nothing", removed)
+	}
+}
@@ -0,0 +1,257 @@
+package changeset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/atomicfile"
+)
+
+// JournalRootEnv overrides the folder journals are kept in.
+const JournalRootEnv = "JENKINS_BACKUP_DIR"
+
+// JournalKeepEnv overrides how many journals Open keeps; 0 keeps them all.
+const JournalKeepEnv = "JENKINS_BACKUP_KEEP"
+
+// DefaultJournalKeep is the number of most recent journals kept.
+const DefaultJournalKeep = 20
+
+// journalFile is the name of the journal inside its folder; backups are
+// stored next to it.
+const journalFile = "journal.json"
+
+// Journal actions: what rollback has to undo.
+const (
+	JournalModified = "modified"
+	JournalCreated  = "created"
+	JournalRemoved  = "removed"
+)
+
+// JournalEntry is one path a run touched. Backup is relative to the
+// journal folder and empty for created paths.
+type JournalEntry struct {
+	Action string `json:"action"`
+	Path   string `json:"path"`
+	Backup string `json:"backup,omitempty"`
+}
+
+// Journal records every path a run modifies, creates or removes, with a
+// backup of its previous content, so the run can be rolled back. It is
+// saved after every entry, so a crashed run still leaves a usable journal.
+type Journal struct {
+	Dir        string         `json:"-"`
+	Tool       string         `json:"tool"`
+	Started    time.Time      `json:"started"`
+	RolledBack bool           `json:"rolledBack,omitempty"`
+	Entries    []JournalEntry `json:"entries"`
+
+	seen map[string]bool
+}
+
+// JournalRoot returns the folder journals are kept in: $JENKINS_BACKUP_DIR,
+// or jenkins-backups in the temporary directory.
+func JournalRoot() string {
+	if dir := os.Getenv(JournalRootEnv); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "jenkins-backups")
+}
+
+// NewJournal creates an empty journal for a run of tool under root.
+func NewJournal(root, tool string) (*Journal, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	dir, err := os.MkdirTemp(root, now.Format("20060102-150405")+"-"+tool+"-")
+	if err != nil {
+		return nil, err
+	}
+	j := &Journal{Dir: dir, Tool: tool, Started: now, Entries: []JournalEntry{}}
+	return j, j.save()
+}
+
+// LoadJournal reads the journal in dir.
+func LoadJournal(dir string) (*Journal, error) {
+	data, err := os.ReadFile(filepath.Join(dir, journalFile))
+	if err != nil {
+		return nil, err
+	}
+	var j Journal
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("journal %s: %w", dir, err)
+	}
+	j.Dir = dir
+	return &j, nil
+}
+
+// Journals returns the journal folders under root, oldest first.
+func Journals(root string) ([]string, error) {
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, e := range entries {
+		dir := filepath.Join(root, e.Name())
+		if _, err := os.Stat(filepath.Join(dir, journalFile)); e.IsDir() && err == nil {
+			dirs = append(dirs, dir)
+		}
+	}
+	// Folder names start with the run's timestamp.
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// PruneJournals deletes the oldest journals under root so that at most keep
+// remain, and returns the folders it deleted. A keep below 1 keeps
+// everything.
+func PruneJournals(root string, keep int) ([]string, error) {
+	if keep < 1 {
+		return nil, nil
+	}
+	dirs, err := Journals(root)
+	if err != nil || len(dirs) <= keep {
+		return nil, err
+	}
+	var removed []string
+	for _, dir := range dirs[:len(dirs)-keep] {
+		if err := os.RemoveAll(dir); err != nil {
+			return removed, err
+		}
+		removed = append(removed, dir)
+	}
+	return removed, nil
+}
+
+// journalKeep reads $JENKINS_BACKUP_KEEP; 0 keeps every journal.
+func journalKeep() int {
+	if v := os.Getenv(JournalKeepEnv); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return n
+		}
+	}
+	return DefaultJournalKeep
+}
+
+// beforeChange backs up path before it is written to or copied over. Only
+// the first change to a path is recorded: that is the state to restore.
+func (j *Journal) beforeChange(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if j.seen[path] {
+		return nil
+	}
+	if _, err := os.Lstat(path); errors.Is(err, os.ErrNotExist) {
+		return j.add(JournalEntry{Action: JournalCreated, Path: path})
+	}
+	backup := j.backupName(path)
+	if err := copyPath(path, filepath.Join(j.Dir, backup)); err != nil {
+		return fmt.Errorf("backing up %s: %w", path, err)
+	}
+	return j.add(JournalEntry{Action: JournalModified, Path: path, Backup: backup})
+}
+
+// remove deletes path by moving it into the journal, falling back to a copy
+// when the journal lives on another filesystem.
+func (j *Journal) remove(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if j.seen[path] {
+		// Already backed up (or created) by this run.
+		return os.RemoveAll(path)
+	}
+	backup := j.backupName(path)
+	dst := filepath.Join(j.Dir, backup)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(path, dst); err != nil {
+		if err := copyPath(path, dst); err != nil {
+			return fmt.Errorf("backing up %s: %w", path, err)
+		}
+	}
+	if err := j.add(JournalEntry{Action: JournalRemoved, Path: path, Backup: backup}); err != nil {
+		return err
+	}
+	return os.RemoveAll(path)
+}
+
+// Rollback restores every recorded path to its state before the run, most
+// recent change first, and returns a description of each step.
+func (j *Journal) Rollback() ([]string, error) {
+	if j.RolledBack {
+		return nil, fmt.Errorf("journal %s was already rolled back", j.Dir)
+	}
+	var steps []string
+	for i := len(j.Entries) - 1; i >= 0; i-- {
+		e := j.Entries[i]
+		if err := os.RemoveAll(e.Path); err != nil {
+			return steps, err
+		}
+		if e.Action == JournalCreated {
+			steps = append(steps, "deleted "+e.Path)
+			continue
+		}
+		if err := copyPath(filepath.Join(j.Dir, e.Backup), e.Path); err != nil {
+			return steps, fmt.Errorf("restoring %s: %w", e.Path, err)
+		}
+		steps = append(steps, "restored "+e.Path)
+	}
+	j.RolledBack = true
+	return steps, j.save()
+}
+
+func (j *Journal) add(e JournalEntry) error {
+	if j.seen == nil {
+		j.seen = map[string]bool{}
+	}
+	j.seen[e.Path] = true
+	j.Entries = append(j.Entries, e)
+	return j.save()
+}
+
+// backupName is where the next backup of path goes, relative to j.Dir.
+func (j *Journal) backupName(path string) string {
+	return filepath.Join("files", strconv.Itoa(len(j.Entries)), filepath.Base(path))
+}
+
+func (j *Journal) save() error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filepath.Join(j.Dir, journalFile), data, 0644)
+}
+
+// copyPath copies a file or a folder tree, creating parent folders.
+func copyPath(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		return copySymlink(src, dst)
+	case info.IsDir():
+		return copyDir(src, dst)
+	}
+	return copyFile(src, dst)
+}
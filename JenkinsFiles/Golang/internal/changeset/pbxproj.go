@@ -0,0 +1,19 @@
+package changeset
+
+import (
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/pbxproj"
+)
+
+// SaveProject writes project to path, listing the objects it created,
+// deleted or modified relative to before (a Clone taken after loading).
+func (p *Plan) SaveProject(path string, project, before *pbxproj.Project) error {
+	data, err := project.Marshal()
+	if err != nil {
+		return err
+	}
+	var details []string
+	for _, c := range pbxproj.ObjectChanges(before, project) {
+		details = append(details, c.String())
+	}
+	return p.WriteFile(path, data, 0644, details...)
+}
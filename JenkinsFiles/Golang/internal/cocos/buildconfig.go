@@ -0,0 +1,320 @@
+package cocos
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// BuildConfig is the buildConfig_ios.json a Creator 3.x command-line build
+// reads through configPath.
+type BuildConfig struct {
+	Platform         string   `json:"platform"`
+	BuildPath        string   `json:"buildPath"`
+	NativeEnginePath string   `json:"nativeEnginePath"`
+	Debug            bool     `json:"debug"`
+	Name             string   `json:"name"`
+	OutputName       string   `json:"outputName"`
+	StartScene       string   `json:"startScene"`
+	Scenes           []Scene  `json:"scenes"`
+	Packages         Packages `json:"packages"`
+}
+
+// Scene is a scene included in the build, by asset URL and UUID.
+type Scene struct {
+	URL  string `json:"url"`
+	UUID string `json:"uuid"`
+}
+
+// Packages holds the per-platform build options.
+type Packages struct {
+	IOS    IOSOptions    `json:"ios"`
+	Native NativeOptions `json:"native"`
+}
+
+// IOSOptions are the iOS options of the Build panel.
+type IOSOptions struct {
+	PackageName   string      `json:"packageName"`
+	Orientation   Orientation `json:"orientation"`
+	OSTarget      OSTarget    `json:"osTarget"`
+	TargetVersion string      `json:"targetVersion"`
+	DeveloperTeam string      `json:"developerTeam"`
+}
+
+// Orientation lists the supported interface orientations.
+type Orientation struct {
+	Portrait       bool `json:"portrait"`
+	UpsideDown     bool `json:"upsideDown"`
+	LandscapeRight bool `json:"landscapeRight"`
+	LandscapeLeft  bool `json:"landscapeLeft"`
+}
+
+// OSTarget selects the device and simulator builds.
+type OSTarget struct {
+	IPhoneOS  bool `json:"iphoneos"`
+	Simulator bool `json:"simulator"`
+}
+
+// NativeOptions are the options shared by the native platforms.
+type NativeOptions struct {
+	Encrypted   bool   `json:"encrypted"`
+	CompressZip bool   `json:"compressZip"`
+	JobSystem   string `json:"JobSystem"`
+}
+
+// BuildConfigInputs are the game-specific values of a build config.
+type BuildConfigInputs struct {
+	BundleID    string
+	ProductName string
+	TeamID      string
+	// TargetVersion is the minimum iOS version; empty means 12.0.
+	TargetVersion string
+	Orientation   Orientation
+	// StartScene names the first scene by UUID, db:// URL, path under
+	// assets or file name; it may be empty when the build has one scene.
+	StartScene string
+	// Scenes restricts the build to these scenes, named like StartScene;
+	// empty means every scene in the project.
+	Scenes []string
+}
+
+// DefaultTargetVersion is the minimum iOS version when none is given.
+const DefaultTargetVersion = "12.0"
+
+// ParseOrientation reads an orientation list such as "portrait",
+// "landscape", "all" or "portrait,landscape-left".
+func ParseOrientation(s string) (Orientation, error) {
+	var o Orientation
+	for _, part := range strings.Split(s, ",") {
+		switch strings.ToLower(strings.TrimSpace(part)) {
+		case "all":
+			o = Orientation{true, true, true, true}
+		case "portrait":
+			o.Portrait = true
+		case "upside-down", "portrait-upside-down":
+			o.UpsideDown = true
+		case "landscape":
+			o.LandscapeLeft, o.LandscapeRight = true, true
+		case "landscape-left":
+			o.LandscapeLeft = true
+		case "landscape-right":
+			o.LandscapeRight = true
+		case "":
+		default:
+			return o, fmt.Errorf("cocos: unknown orientation %q (want portrait, upside-down, landscape, landscape-left, landscape-right or all)", part)
+		}
+	}
+	return o, nil
+}
+
+// productNameChars are the characters Creator accepts in a project name, which
+// also names the generated Xcode project.
+var productNameChars = regexp.MustCompile(`[^A-Za-z0-9]`)
+
+// SanitizeName strips a product name down to what Creator accepts, like
+// SetupCocosBuildSettings.py does for 2.x projects.
+func SanitizeName(name string) string {
+	return productNameChars.ReplaceAllString(name, "")
+}
+
+// NewBuildConfig fills a build config from inputs and the scenes of the
+// project, then validates it.
+func (p *Project) NewBuildConfig(in BuildConfigInputs) (*BuildConfig, error) {
+	all, err := p.Scenes()
+	if err != nil {
+		return nil, err
+	}
+	scenes := all
+	if len(in.Scenes) > 0 {
+		scenes = nil
+		for _, name := range in.Scenes {
+			scene, err := findScene(all, name)
+			if err != nil {
+				return nil, err
+			}
+			scenes = append(scenes, scene)
+		}
+	}
+
+	var start Scene
+	switch {
+	case in.StartScene != "":
+		if start, err = findScene(scenes, in.StartScene); err != nil {
+			return nil, err
+		}
+	case len(scenes) == 1:
+		start = scenes[0]
+	default:
+		return nil, fmt.Errorf("cocos: %d scenes in the build, choose the start scene among %s", len(scenes), sceneURLs(scenes))
+	}
+
+	targetVersion := in.TargetVersion
+	if targetVersion == "" {
+		targetVersion = DefaultTargetVersion
+	}
+	c := &BuildConfig{
+		Platform:         "ios",
+		BuildPath:        "project://build",
+		NativeEnginePath: "project://native",
+		Name:             SanitizeName(in.ProductName),
+		OutputName:       "ios",
+		StartScene:       start.UUID,
+		Scenes:           scenes,
+		Packages: Packages{
+			IOS: IOSOptions{
+				PackageName:   in.BundleID,
+				Orientation:   in.Orientation,
+				OSTarget:      OSTarget{IPhoneOS: true},
+				TargetVersion: targetVersion,
+				DeveloperTeam: in.TeamID,
+			},
+			Native: NativeOptions{JobSystem: "tbb"},
+		},
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+var (
+	bundleIDPattern      = regexp.MustCompile(`^[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)+$`)
+	teamIDPattern        = regexp.MustCompile(`^[A-Z0-9]{10}$`)
+	targetVersionPattern = regexp.MustCompile(`^\d+(\.\d+){0,2}$`)
+)
+
+// Validate checks the config against what Creator and Xcode accept and
+// lists every problem found.
+func (c *BuildConfig) Validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.Platform != "ios" {
+		add("platform is %q, want \"ios\"", c.Platform)
+	}
+	if c.Name == "" {
+		add("name is empty (the product name has no letters or digits)")
+	} else if SanitizeName(c.Name) != c.Name {
+		add("name %q may only hold letters and digits", c.Name)
+	}
+	if c.OutputName == "" {
+		add("outputName is empty")
+	}
+
+	ios := c.Packages.IOS
+	if !bundleIDPattern.MatchString(ios.PackageName) {
+		add("packageName %q is not a bundle identifier (letters, digits, '-' and '.', at least two parts)", ios.PackageName)
+	}
+	if ios.DeveloperTeam != "" && !teamIDPattern.MatchString(ios.DeveloperTeam) {
+		add("developerTeam %q is not a 10-character team ID", ios.DeveloperTeam)
+	}
+	if !targetVersionPattern.MatchString(ios.TargetVersion) {
+		add("targetVersion %q is not a version such as 12.0", ios.TargetVersion)
+	}
+	if ios.Orientation == (Orientation{}) {
+		add("no orientation enabled")
+	}
+	if ios.OSTarget == (OSTarget{}) {
+		add("neither iphoneos nor simulator enabled")
+	}
+
+	if len(c.Scenes) == 0 {
+		add("no scenes")
+	}
+	seen := map[string]bool{}
+	for _, s := range c.Scenes {
+		switch {
+		case s.UUID == "":
+			add("scene %s has no uuid", s.URL)
+		case seen[s.UUID]:
+			add("scene %s is listed twice", s.URL)
+		}
+		seen[s.UUID] = true
+	}
+	if !seen[c.StartScene] {
+		add("startScene %q is not among the scenes", c.StartScene)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("cocos: invalid build config:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// Marshal encodes the config the way Creator saves it.
+func (c *BuildConfig) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Scenes lists the .scene assets of a 3.x project with the UUIDs from their
+// .meta files, sorted by URL.
+func (p *Project) Scenes() ([]Scene, error) {
+	assets := filepath.Join(p.Dir, "assets")
+	var scenes []Scene
+	err := filepath.Walk(assets, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".scene" {
+			return nil
+		}
+		var meta struct {
+			UUID string `json:"uuid"`
+		}
+		if err := readJSON(path+".meta", &meta); err != nil {
+			return fmt.Errorf("cocos: scene %s: %w", path, err)
+		}
+		rel, err := filepath.Rel(assets, path)
+		if err != nil {
+			return err
+		}
+		scenes = append(scenes, Scene{URL: "db://assets/" + filepath.ToSlash(rel), UUID: meta.UUID})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(scenes) == 0 {
+		return nil, fmt.Errorf("cocos: no .scene files under %s", assets)
+	}
+	sort.Slice(scenes, func(i, j int) bool { return scenes[i].URL < scenes[j].URL })
+	return scenes, nil
+}
+
+// findScene picks the scene named by UUID, URL, path under assets or base
+// name, with or without the .scene extension.
+func findScene(scenes []Scene, name string) (Scene, error) {
+	want := strings.TrimSuffix(strings.TrimPrefix(name, "db://assets/"), ".scene")
+	var matches []Scene
+	for _, s := range scenes {
+		path := strings.TrimSuffix(strings.TrimPrefix(s.URL, "db://assets/"), ".scene")
+		if s.UUID == name || path == want || (!strings.Contains(want, "/") && filepath.Base(path) == want) {
+			matches = append(matches, s)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return Scene{}, fmt.Errorf("cocos: no scene %q among %s", name, sceneURLs(scenes))
+	}
+	return Scene{}, fmt.Errorf("cocos: scene %q is ambiguous: %s", name, sceneURLs(matches))
+}
+
+func sceneURLs(scenes []Scene) string {
+	urls := make([]string, len(scenes))
+	for i, s := range scenes {
+		urls[i] = s.URL
+	}
+	return strings.Join(urls, ", ")
+}
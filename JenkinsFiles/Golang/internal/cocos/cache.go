@@ -0,0 +1,138 @@
+package cocos
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// CacheStampFile is written into library/ after a successful build. Keeping
+// it inside the cache means deleting library/ also forgets the stamp.
+const CacheStampFile = "jenkins-cache.json"
+
+// CacheStamp records what the imported assets in library/ and temp/ were
+// built from.
+type CacheStamp struct {
+	CreatorVersion string `json:"creatorVersion"`
+	// Assets and Settings are digests of the file contents of assets/ and
+	// of the project settings.
+	Assets   string `json:"assets"`
+	Settings string `json:"settings"`
+}
+
+// settingsPaths are the project files and folders whose changes invalidate
+// the import: engine modules, TypeScript options, project settings.
+var settingsPaths = []string{"package.json", "project.json", "tsconfig.json", "settings"}
+
+// ignoredFiles never affect an import. builder.json only holds build panel
+// options, and the pipeline rewrites it on every run.
+var ignoredFiles = map[string]bool{".DS_Store": true, "Thumbs.db": true, "builder.json": true}
+
+// CacheStamp computes the stamp of the project as it is on disk now.
+func (p *Project) CacheStamp() (*CacheStamp, error) {
+	assets, err := digest(p.Dir, []string{"assets"})
+	if err != nil {
+		return nil, err
+	}
+	settings, err := digest(p.Dir, settingsPaths)
+	if err != nil {
+		return nil, err
+	}
+	return &CacheStamp{CreatorVersion: p.Version, Assets: assets, Settings: settings}, nil
+}
+
+// CacheStampPath is where the stamp of the current library/ is kept.
+func (p *Project) CacheStampPath() string {
+	return filepath.Join(p.Dir, "library", CacheStampFile)
+}
+
+// StaleCache compares current with the stamp left by the last successful
+// build and returns why library/ and temp/ cannot be reused; none means the
+// cache is valid.
+func (p *Project) StaleCache(current *CacheStamp) ([]string, error) {
+	data, err := os.ReadFile(p.CacheStampPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{"no cache stamp from a previous successful build"}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cocos: %w", err)
+	}
+	var stored CacheStamp
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return []string{fmt.Sprintf("unreadable cache stamp: %v", err)}, nil
+	}
+
+	var reasons []string
+	if stored.CreatorVersion != current.CreatorVersion {
+		reasons = append(reasons, fmt.Sprintf("Creator version changed from %s to %s", stored.CreatorVersion, current.CreatorVersion))
+	}
+	if stored.Assets != current.Assets {
+		reasons = append(reasons, "assets changed")
+	}
+	if stored.Settings != current.Settings {
+		reasons = append(reasons, "project settings changed")
+	}
+	return reasons, nil
+}
+
+// Marshal encodes the stamp for CacheStampPath.
+func (s *CacheStamp) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// digest hashes the relative path and contents of every file under the
+// given paths of dir, in a stable order. Missing paths are skipped.
+func digest(dir string, paths []string) (string, error) {
+	h := sha256.New()
+	for _, path := range paths {
+		root := filepath.Join(dir, path)
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) && path == root {
+					return nil
+				}
+				return err
+			}
+			if !d.Type().IsRegular() || ignoredFiles[d.Name()] {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			sum, err := fileDigest(path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "%s\x00%s\n", filepath.ToSlash(rel), sum)
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("cocos: hashing %s: %w", root, err)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,302 @@
+// Package cocos knows how the Cocos Creator major versions differ for an iOS
+// build: command line, output layout and icon locations. The profile is
+// picked from the project's own version metadata.
+package cocos
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Profile is the build recipe of one Creator major version.
+type Profile struct {
+	// Name matches the Jenkinsfile's COCOS_VERSION choice.
+	Name string
+	// Major is the Creator major version.
+	Major int
+	// DefaultVersion is assumed when the project does not say.
+	DefaultVersion string
+	// CacheDirs hold the imported assets and are only deleted when the
+	// cache is stale. The build output is OutputDir.
+	CacheDirs []string
+}
+
+// DashboardRoot is where Cocos Dashboard installs every Creator version,
+// 2.x and 3.x alike, as <version>/CocosCreator.app.
+const DashboardRoot = "/Applications/Cocos/Creator"
+
+var (
+	// Creator3 builds with --project and a buildConfig_ios.json, into
+	// <buildPath>/<outputName>/proj, with the icons in native/engine/ios.
+	Creator3 = &Profile{
+		Name:           "cocos3",
+		Major:          3,
+		DefaultVersion: "3.7.3",
+		CacheDirs:      []string{"library", "temp"},
+	}
+	// Creator2 builds with --path and the settings in settings/builder.json
+	// and local/builder.json, into <buildPath>/jsb-<template>, with the icons
+	// inside the generated Xcode project.
+	Creator2 = &Profile{
+		Name:           "cocos2",
+		Major:          2,
+		DefaultVersion: "2.4.11",
+		// local/ holds builder.json, so it is not a cache.
+		CacheDirs: []string{"library", "temp"},
+	}
+)
+
+var profiles = []*Profile{Creator2, Creator3}
+
+// Project is a Creator project with its detected version.
+type Project struct {
+	Dir string
+	// Version is the Creator version the project was saved with, or the
+	// profile's default when the metadata is missing.
+	Version string
+	Profile *Profile
+	// VersionSource tells where Version came from, for logs.
+	VersionSource string
+}
+
+// Open detects the Creator version of the project in dir. A non-empty
+// override ("cocos2", "cocos3", "2", "3" or a full version such as "2.4.11")
+// takes precedence over the project metadata.
+func Open(dir, override string) (*Project, error) {
+	p := &Project{Dir: dir}
+	if override != "" {
+		profile, version, err := parseVersion(override)
+		if err != nil {
+			return nil, err
+		}
+		p.Profile, p.Version, p.VersionSource = profile, version, "override"
+		if p.Version == "" {
+			if detected, source, err := detectVersion(dir); err == nil && majorOf(detected) == profile.Major {
+				p.Version, p.VersionSource = detected, source
+			} else {
+				p.Version = profile.DefaultVersion
+			}
+		}
+		return p, nil
+	}
+
+	version, source, err := detectVersion(dir)
+	if err != nil {
+		return nil, err
+	}
+	profile, _, err := parseVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("cocos: %s: %w", source, err)
+	}
+	p.Profile, p.Version, p.VersionSource = profile, version, source
+	return p, nil
+}
+
+// parseVersion maps a version or profile name to its profile. The returned
+// version is empty when only a profile was named.
+func parseVersion(v string) (*Profile, string, error) {
+	for _, profile := range profiles {
+		if v == profile.Name || v == fmt.Sprint(profile.Major) {
+			return profile, "", nil
+		}
+	}
+	major := majorOf(v)
+	for _, profile := range profiles {
+		if major == profile.Major {
+			return profile, v, nil
+		}
+	}
+	return nil, "", fmt.Errorf("cocos: unsupported Creator version %q (want cocos2, cocos3 or a 2.x/3.x version)", v)
+}
+
+func majorOf(version string) int {
+	var major int
+	fmt.Sscanf(strings.TrimPrefix(version, "v"), "%d", &major)
+	return major
+}
+
+// detectVersion reads the Creator version from package.json (3.x:
+// "creator": {"version": ...}) or project.json (2.x: "version").
+func detectVersion(dir string) (version, source string, err error) {
+	var pkg struct {
+		Creator struct {
+			Version string `json:"version"`
+		} `json:"creator"`
+	}
+	path := filepath.Join(dir, "package.json")
+	if err := readJSON(path, &pkg); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", "", err
+	}
+	if pkg.Creator.Version != "" {
+		return pkg.Creator.Version, path, nil
+	}
+
+	var project struct {
+		Version string `json:"version"`
+	}
+	path = filepath.Join(dir, "project.json")
+	if err := readJSON(path, &project); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", "", err
+	}
+	if project.Version != "" {
+		return project.Version, path, nil
+	}
+	return "", "", fmt.Errorf("cocos: no Creator version in %s/package.json or project.json; set the Cocos version explicitly", dir)
+}
+
+// CreatorPath is where Cocos Dashboard installs the project's version.
+func (p *Project) CreatorPath() string {
+	return filepath.Join(DashboardRoot, p.Version, "CocosCreator.app", "Contents", "MacOS", "CocosCreator")
+}
+
+// BuildConfigPath is the buildConfig_ios.json a 3.x build reads; 2.x builds
+// have none.
+func (p *Project) BuildConfigPath() string {
+	if p.Profile.Major != 3 {
+		return ""
+	}
+	return filepath.Join(p.Dir, "buildConfig_ios.json")
+}
+
+// BuildArgs are the Creator command-line arguments of an iOS release build.
+func (p *Project) BuildArgs() []string {
+	if p.Profile.Major == 2 {
+		// The rest of the options come from the builder.json files.
+		return []string{"--path", p.Dir, "--build", "platform=ios;debug=false"}
+	}
+	return []string{"--project", p.Dir, "--build", "platform=ios;debug=false;configPath=" + p.BuildConfigPath()}
+}
+
+// XcodeDir is the folder the build generates the .xcodeproj in.
+func (p *Project) XcodeDir() string {
+	if p.Profile.Major == 2 {
+		return filepath.Join(p.buildDir(), "jsb-"+p.template(), "frameworks", "runtime-src", "proj.ios_mac")
+	}
+	return filepath.Join(p.buildDir(), p.outputName(), "proj")
+}
+
+// AssetCatalog is the Images.xcassets the Xcode project uses.
+func (p *Project) AssetCatalog() string {
+	if p.Profile.Major == 2 {
+		return filepath.Join(p.XcodeDir(), "ios", "Images.xcassets")
+	}
+	return filepath.Join(p.Dir, "native", "engine", "ios", "Images.xcassets")
+}
+
+// IconSet is the AppIcon.appiconset the Xcode project uses.
+func (p *Project) IconSet() string {
+	return filepath.Join(p.AssetCatalog(), "AppIcon.appiconset")
+}
+
+// ProductName is the name the build gives the generated .xcodeproj: the
+// name in buildConfig_ios.json for 3.x, the title in settings/builder.json
+// for 2.x. It is empty while those files are missing.
+func (p *Project) ProductName() string {
+	var settings struct {
+		Name  string `json:"name"`
+		Title string `json:"title"`
+	}
+	if p.Profile.Major == 2 {
+		readJSON(filepath.Join(p.Dir, "settings", "builder.json"), &settings)
+		return settings.Title
+	}
+	readJSON(p.BuildConfigPath(), &settings)
+	return settings.Name
+}
+
+// localBuilder is the part of a 2.x local/builder.json that moves the output.
+type localBuilder struct {
+	BuildPath string `json:"buildPath"`
+	Template  string `json:"template"`
+}
+
+func (p *Project) localBuilder() localBuilder {
+	var b localBuilder
+	readJSON(filepath.Join(p.Dir, "local", "builder.json"), &b)
+	return b
+}
+
+// buildPath is the output folder as the Build panel saved it: buildPath in
+// local/builder.json for 2.x, in buildConfig_ios.json for 3.x.
+func (p *Project) buildPath() (path, source string) {
+	if p.Profile.Major == 2 {
+		return p.localBuilder().BuildPath, filepath.Join(p.Dir, "local", "builder.json")
+	}
+	var c BuildConfig
+	readJSON(p.BuildConfigPath(), &c)
+	return c.BuildPath, p.BuildConfigPath()
+}
+
+// buildDir resolves buildPath: unset is build/, project:// and relative
+// paths are in the project.
+func (p *Project) buildDir() string {
+	dir, _ := p.buildPath()
+	if dir == "" {
+		return filepath.Join(p.Dir, "build")
+	}
+	dir = strings.TrimPrefix(dir, "project://")
+	if filepath.IsAbs(dir) {
+		return filepath.Clean(dir)
+	}
+	return filepath.Join(p.Dir, dir)
+}
+
+// outputName is the 3.x build task folder inside the build dir.
+func (p *Project) outputName() string {
+	var c BuildConfig
+	readJSON(p.BuildConfigPath(), &c)
+	if c.OutputName != "" {
+		return c.OutputName
+	}
+	return "ios"
+}
+
+// OutputDir is the folder the build regenerates, and so the one cleaned
+// before it. It must be a subfolder of the project: anything else would
+// have the clean delete the project or files outside it.
+func (p *Project) OutputDir() (string, error) {
+	dir := p.buildDir()
+	rel, err := filepath.Rel(p.Dir, dir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		path, source := p.buildPath()
+		return "", fmt.Errorf("cocos: the build path %q in %s is not a folder inside the project %s", path, source, p.Dir)
+	}
+	return dir, nil
+}
+
+func (p *Project) template() string {
+	if t := p.localBuilder().Template; t != "" {
+		return t
+	}
+	return "default"
+}
+
+// Check reports what the build will be missing before Creator is started.
+func (p *Project) Check() error {
+	if p.Profile.Major == 2 {
+		path := filepath.Join(p.Dir, "settings", "builder.json")
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("cocos: Creator 2.x builds need %s, saved by the Build panel", path)
+		}
+	}
+	return nil
+}
+
+func (p *Project) String() string {
+	return fmt.Sprintf("Creator %s (%s, from %s)", p.Version, p.Profile.Name, p.VersionSource)
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("cocos: %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package cocos
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOutputDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		profile  *Profile
+		file     string
+		content  string
+		wantDir  string
+		wantProj string
+	}{
+		{"3.x default", Creator3, "", "", "build", "build/ios/proj"},
+		{"3.x project URL", Creator3, "buildConfig_ios.json",
+			`{"buildPath": "project://out/native", "outputName": "iphone"}`,
+			"out/native", "out/native/iphone/proj"},
+		{"3.x relative", Creator3, "buildConfig_ios.json",
+			`{"buildPath": "CocosBuild"}`, "CocosBuild", "CocosBuild/ios/proj"},
+		{"2.x default", Creator2, "", "", "build", "build/jsb-default/frameworks/runtime-src/proj.ios_mac"},
+		{"2.x local builder", Creator2, "local/builder.json",
+			`{"buildPath": "CocosBuild", "template": "link"}`,
+			"CocosBuild", "CocosBuild/jsb-link/frameworks/runtime-src/proj.ios_mac"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.file != "" {
+				writeFile(t, filepath.Join(dir, tt.file), tt.content)
+			}
+			p := &Project{Dir: dir, Profile: tt.profile}
+			got, err := p.OutputDir()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := filepath.Join(dir, tt.wantDir); got != want {
+				t.Errorf("OutputDir() = %s, want %s", got, want)
+			}
+			if want := filepath.Join(dir, tt.wantProj); p.XcodeDir() != want {
+				t.Errorf("XcodeDir() = %s, want %s", p.XcodeDir(), want)
+			}
+		})
+	}
+}
+
+func TestOutputDirOutsideProject(t *testing.T) {
+	for _, buildPath := range []string{"project://", ".", "..", "../build", "/tmp"} {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "buildConfig_ios.json"), `{"buildPath": "`+buildPath+`"}`)
+		p := &Project{Dir: dir, Profile: Creator3}
+		if got, err := p.OutputDir(); err == nil {
+			t.Errorf("buildPath %q: OutputDir() = %s, want an error", buildPath, got)
+		}
+	}
+}
+
+func TestCreatorPath(t *testing.T) {
+	for _, p := range []*Project{
+		{Version: "2.4.11", Profile: Creator2},
+		{Version: "3.8.2", Profile: Creator3},
+	} {
+		want := "/Applications/Cocos/Creator/" + p.Version + "/CocosCreator.app/Contents/MacOS/CocosCreator"
+		if got := p.CreatorPath(); got != want {
+			t.Errorf("%s: CreatorPath() = %s, want %s", p.Version, got, want)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+// Package cocoslog parses the console output of Cocos Creator 2.x and 3.x
+// command-line builds into stages, warnings and errors, so a failed build
+// can be reported by its cause instead of by dumping the whole log.
+package cocoslog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Severity ranks the problems found in a log.
+type Severity int
+
+const (
+	Warning Severity = iota
+	Error
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// MarshalText makes severities readable in the JSON result.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Problem kinds reported by Parse.
+const (
+	KindTypeScript   = "typescript"
+	KindMissingAsset = "missing-asset"
+	KindNative       = "native"
+	KindOther        = "other"
+)
+
+// Problem is one warning or error line of the log, with the source location
+// when the compiler gave one.
+type Problem struct {
+	Severity Severity `json:"severity"`
+	Kind     string   `json:"kind"`
+	Message  string   `json:"message"`
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	// Code is the compiler's diagnostic code, e.g. TS2304.
+	Code string `json:"code,omitempty"`
+	// UUID is the asset a missing-asset error refers to.
+	UUID string `json:"uuid,omitempty"`
+	// LogLine is the 1-based line of the log the problem was read from.
+	LogLine int `json:"logLine"`
+}
+
+func (p Problem) String() string {
+	var b strings.Builder
+	if p.Code != "" {
+		b.WriteString(p.Code + " ")
+	}
+	if p.File != "" {
+		b.WriteString(p.File)
+		if p.Line > 0 {
+			fmt.Fprintf(&b, ":%d", p.Line)
+			if p.Column > 0 {
+				fmt.Fprintf(&b, ":%d", p.Column)
+			}
+		}
+		b.WriteString(": ")
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// Stage is a build step announced in the log.
+type Stage struct {
+	Name    string `json:"name"`
+	LogLine int    `json:"logLine"`
+}
+
+// Result is everything Parse learned from a log.
+type Result struct {
+	// Success is set when Creator reported a successful build. Creator logs
+	// some errors without failing, so Errors may be non-empty even then.
+	Success        bool      `json:"success"`
+	CreatorVersion string    `json:"creatorVersion,omitempty"`
+	Lines          int       `json:"lines"`
+	Stages         []Stage   `json:"stages,omitempty"`
+	Errors         []Problem `json:"errors,omitempty"`
+	Warnings       []Problem `json:"warnings,omitempty"`
+	// Tail holds the last lines of the log, the best clue left when a build
+	// fails without printing any recognizable error.
+	Tail []string `json:"tail,omitempty"`
+}
+
+// tailLines is how many trailing lines Result.Tail keeps.
+const tailLines = 20
+
+var (
+	ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+	successLine = regexp.MustCompile(`(?i)\bbuild success|\bbuilt to .* successfully|\bbuild (?:finished|completed) successfully`)
+	versionLine = regexp.MustCompile(`(?i)\b(?:cocos\s*)?creator\s*(?:version\s*)?[:=]?\s*v?(\d+\.\d+\.\d+)`)
+
+	// Creator 3.x: "// ---- build task ios：onBeforeBuild ----".
+	taskStage = regexp.MustCompile(`^//\s*-+\s*build task\s+(.+?)\s*-+(?:\s*\(\d+\s*m?s\))?$`)
+	// Both versions: "Start build ...", "Building assets...", "Compiling scripts".
+	startStage = regexp.MustCompile(`(?i)^(?:start(?:ing)?|begin(?:ning)?)\s+(?:to\s+)?(.+?)\.*$|^((?:build|compil|pack|export|generat|copy|compress)ing\s+.+?)\s*(?:\.\.\.|…)\s*$`)
+	// Native build steps driven by cmake and xcodebuild.
+	cmakeStage     = regexp.MustCompile(`^-- (Configuring|Generating|Build files have been written)`)
+	xcodebuildStep = regexp.MustCompile(`^=== BUILD TARGET (\S+)`)
+
+	// tsc: "assets/a.ts(12,5): error TS2304: ..." and "assets/a.ts:12:5 - error TS2304: ...".
+	tsParen = regexp.MustCompile(`^(.+?\.tsx?)\((\d+),(\d+)\):\s*(error|warning)\s+(TS\d+):\s*(.*)$`)
+	tsColon = regexp.MustCompile(`^(.+?\.tsx?):(\d+):(\d+)\s+-\s+(error|warning)\s+(TS\d+):\s*(.*)$`)
+	// clang and cmake diagnostics on native sources.
+	clangDiag = regexp.MustCompile(`^(.+?\.(?:c|cc|cpp|cxx|h|hh|hpp|m|mm|swift)):(\d+):(?:(\d+):)?\s*(fatal error|error|warning):\s*(.*)$`)
+	cmakeDiag = regexp.MustCompile(`^CMake (Error|Warning)(?: \(dev\))? at (.+?):(\d+)`)
+	nativeErr = regexp.MustCompile(`^(?:\*\* (?:BUILD|ARCHIVE) FAILED \*\*|ld: .*|clang(?:\+\+)?: error: .*|xcodebuild: error: .*|error: linker command failed.*|make(?:\[\d+\])?: \*\*\* .*)$`)
+
+	missingAsset = regexp.MustCompile(`(?i)missing (?:asset|script|dependenc(?:y|ies)|uuid)|asset\b.*\b(?:is missing|not found|does not exist)|can ?not (?:find|load) (?:the )?(?:asset|uuid|script)|uuid\b.*\b(?:not found|does not exist|is missing)`)
+	uuidPattern  = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}(?:@[0-9a-z]+)?`)
+
+	// Generic "error:"/"[error]"/"warn:" markers, after any timestamp prefix.
+	errorMarker   = regexp.MustCompile(`(?i)(?:^|[\s\[\]\-])(?:error|err!|fatal)\s*[:\]!]\s*(.*)$|^(?:\w+)?Error:\s*(.*)$`)
+	warningMarker = regexp.MustCompile(`(?i)(?:^|[\s\[\]\-])(?:warn|warning)\s*[:\]]\s*(.*)$`)
+)
+
+// ParseFile parses the log at path.
+func ParseFile(path string) (*Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cocoslog: %w", err)
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads a Creator build log.
+func Parse(r io.Reader) (*Result, error) {
+	p := NewParser()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		p.Line(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cocoslog: reading log: %w", err)
+	}
+	return p.Result(), nil
+}
+
+// Parser classifies a log one line at a time, for callers that stream the
+// output of a running build.
+type Parser struct {
+	result Result
+	seen   map[string]bool
+}
+
+// NewParser returns an empty Parser.
+func NewParser() *Parser {
+	return &Parser{seen: map[string]bool{}}
+}
+
+// Line feeds the next line of the log.
+func (p *Parser) Line(raw string) {
+	p.result.Lines++
+	n := p.result.Lines
+	line := strings.TrimSpace(ansiEscape.ReplaceAllString(raw, ""))
+	if line == "" {
+		return
+	}
+
+	p.result.Tail = append(p.result.Tail, line)
+	if len(p.result.Tail) > tailLines {
+		p.result.Tail = p.result.Tail[1:]
+	}
+
+	if successLine.MatchString(line) {
+		p.result.Success = true
+	}
+	if p.result.CreatorVersion == "" {
+		if m := versionLine.FindStringSubmatch(line); m != nil {
+			p.result.CreatorVersion = m[1]
+		}
+	}
+	// A stage line may carry a diagnostic too, so it is classified as well.
+	if name := stageName(stripPrefix(line)); name != "" {
+		p.result.Stages = append(p.result.Stages, Stage{Name: name, LogLine: n})
+	}
+	if problem, ok := classify(line); ok {
+		problem.LogLine = n
+		p.add(problem)
+	}
+}
+
+// Result returns what was learned so far.
+func (p *Parser) Result() *Result {
+	r := p.result
+	return &r
+}
+
+// add records problem unless the same diagnostic was already seen: tsc and
+// clang often repeat errors once per including file or build pass.
+func (p *Parser) add(problem Problem) {
+	key := fmt.Sprint(problem.Severity, problem.Kind, problem.File, problem.Line, problem.Column, problem.Message)
+	if p.seen[key] {
+		return
+	}
+	p.seen[key] = true
+	if problem.Severity == Error {
+		p.result.Errors = append(p.result.Errors, problem)
+	} else {
+		p.result.Warnings = append(p.result.Warnings, problem)
+	}
+}
+
+func stageName(line string) string {
+	if m := taskStage.FindStringSubmatch(line); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+	if m := xcodebuildStep.FindStringSubmatch(line); m != nil {
+		return "xcodebuild " + m[1]
+	}
+	if m := cmakeStage.FindStringSubmatch(line); m != nil {
+		return "cmake " + strings.ToLower(m[1])
+	}
+	if m := startStage.FindStringSubmatch(line); m != nil {
+		if m[1] != "" {
+			return m[1]
+		}
+		return m[2]
+	}
+	return ""
+}
+
+func classify(line string) (Problem, bool) {
+	// Compiler diagnostics may follow a "[timestamp] error:"-style prefix.
+	body := stripPrefix(line)
+
+	for _, re := range []*regexp.Regexp{tsParen, tsColon} {
+		if m := re.FindStringSubmatch(body); m != nil {
+			return Problem{
+				Severity: severity(m[4]),
+				Kind:     KindTypeScript,
+				File:     m[1],
+				Line:     atoi(m[2]),
+				Column:   atoi(m[3]),
+				Code:     m[5],
+				Message:  m[6],
+			}, true
+		}
+	}
+	if m := clangDiag.FindStringSubmatch(body); m != nil {
+		return Problem{
+			Severity: severity(m[4]),
+			Kind:     KindNative,
+			File:     m[1],
+			Line:     atoi(m[2]),
+			Column:   atoi(m[3]),
+			Message:  m[5],
+		}, true
+	}
+	if m := cmakeDiag.FindStringSubmatch(body); m != nil {
+		// The details follow on indented lines; keep the "(command)" part.
+		message := "CMake " + strings.ToLower(m[1])
+		if rest := strings.TrimSuffix(strings.TrimSpace(body[len(m[0]):]), ":"); rest != "" {
+			message += " " + rest
+		}
+		return Problem{
+			Severity: severity(m[1]),
+			Kind:     KindNative,
+			File:     m[2],
+			Line:     atoi(m[3]),
+			Message:  message,
+		}, true
+	}
+	if nativeErr.MatchString(body) {
+		return Problem{Severity: Error, Kind: KindNative, Message: body}, true
+	}
+
+	isError := errorMarker.MatchString(line)
+	isWarning := !isError && warningMarker.MatchString(line)
+	if missingAsset.MatchString(line) {
+		problem := Problem{Severity: Error, Kind: KindMissingAsset, Message: body}
+		if isWarning {
+			problem.Severity = Warning
+		}
+		problem.UUID = uuidPattern.FindString(line)
+		return problem, true
+	}
+	switch {
+	case isError:
+		return Problem{Severity: Error, Kind: KindOther, Message: body}, true
+	case isWarning:
+		return Problem{Severity: Warning, Kind: KindOther, Message: body}, true
+	}
+	return Problem{}, false
+}
+
+// logPrefix matches the timestamp and level decorations Creator puts in front
+// of its messages, e.g. "[2024-05-10 12:00:01] [error] " or "12:00:01 - error: ".
+var logPrefix = regexp.MustCompile(`(?i)^(?:\[[^\]]*\]\s*|\d{1,4}[-/:.]\d{1,2}[-/:.]\d{1,4}[\d\s:.,T-]*(?:-\s*)?)+(?:(?:log|info|debug|warn|warning|error|err!|fatal)\s*[:\]!]?\s*)?`)
+
+func stripPrefix(line string) string {
+	if stripped := logPrefix.ReplaceAllString(line, ""); stripped != "" {
+		return stripped
+	}
+	return line
+}
+
+func severity(word string) Severity {
+	if strings.Contains(strings.ToLower(word), "error") {
+		return Error
+	}
+	return Warning
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
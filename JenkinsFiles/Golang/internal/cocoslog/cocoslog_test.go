@@ -0,0 +1,78 @@
+package cocoslog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStageLineWithError(t *testing.T) {
+	r, err := Parse(strings.NewReader(strings.Join([]string{
+		"// ---- build task ios：onBeforeBuild ----",
+		"[2024-05-10 12:00:01] [error] Compiling scripts...",
+		"[2024-05-10 12:00:02] [warn] Building assets...",
+	}, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stages []string
+	for _, s := range r.Stages {
+		stages = append(stages, s.Name)
+	}
+	if got := strings.Join(stages, "|"); got != "ios：onBeforeBuild|Compiling scripts|Building assets" {
+		t.Errorf("stages = %q", got)
+	}
+	if len(r.Errors) != 1 || r.Errors[0].LogLine != 2 {
+		t.Errorf("errors = %+v, want the stage line 2", r.Errors)
+	}
+	if len(r.Warnings) != 1 || r.Warnings[0].LogLine != 3 {
+		t.Errorf("warnings = %+v, want the stage line 3", r.Warnings)
+	}
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	r, err := Parse(strings.NewReader(strings.Join([]string{
+		"Cocos Creator version: 3.8.2",
+		"assets/scripts/Game.ts(12,5): error TS2304: Cannot find name 'foo'.",
+		"assets/scripts/Game.ts(12,5): error TS2304: Cannot find name 'foo'.",
+		"/src/native/Foo.mm:40:3: warning: unused variable 'x'",
+		"** BUILD FAILED **",
+	}, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.CreatorVersion != "3.8.2" {
+		t.Errorf("CreatorVersion = %q", r.CreatorVersion)
+	}
+	if r.Success {
+		t.Error("Success set for a failed build")
+	}
+	if len(r.Errors) != 2 {
+		t.Fatalf("errors = %+v, want the TypeScript error once and BUILD FAILED", r.Errors)
+	}
+	ts := r.Errors[0]
+	if ts.Kind != KindTypeScript || ts.File != "assets/scripts/Game.ts" || ts.Line != 12 || ts.Column != 5 || ts.Code != "TS2304" {
+		t.Errorf("TypeScript error = %+v", ts)
+	}
+	if len(r.Warnings) != 1 || r.Warnings[0].Line != 40 {
+		t.Errorf("warnings = %+v", r.Warnings)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cocos_build.json")
+	r := &Result{Success: true, Lines: 3}
+	if err := r.WriteJSON(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Result
+	if err := json.Unmarshal(data, &back); err != nil || !back.Success || back.Lines != 3 {
+		t.Errorf("round trip = %+v, %v", back, err)
+	}
+}
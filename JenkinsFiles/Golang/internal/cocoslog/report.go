@@ -0,0 +1,94 @@
+package cocoslog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/atomicfile"
+)
+
+// creatorExitCodes are the documented exit codes of a Creator 3.x
+// command-line build. Creator 3 exits with 36 on success, so a non-zero
+// status alone does not mean the build failed.
+var creatorExitCodes = map[int]string{
+	32: "build failed: invalid build parameters",
+	34: "build failed: unexpected error",
+	36: "build succeeded",
+}
+
+// DescribeExitCode explains a Creator exit code, or returns "" when it has no
+// documented meaning.
+func DescribeExitCode(code int) string {
+	return creatorExitCodes[code]
+}
+
+// Reason is a one-line explanation of a failed build: its first error, or
+// the last line of the log when no error was recognized.
+func (r *Result) Reason() string {
+	switch {
+	case len(r.Errors) > 0:
+		reason := r.Errors[0].String()
+		if more := len(r.Errors) - 1; more > 0 {
+			reason += fmt.Sprintf(" (and %d more error(s))", more)
+		}
+		return reason
+	case len(r.Tail) > 0:
+		return r.Tail[len(r.Tail)-1]
+	}
+	return "empty build log"
+}
+
+// WriteSummary prints the stages reached and up to max errors and warnings;
+// max <= 0 prints them all. A failed build without recognized errors gets
+// the tail of the log instead.
+func (r *Result) WriteSummary(w io.Writer, max int) {
+	status := "✅ Build succeeded"
+	if !r.Success {
+		status = "❌ Build failed"
+	}
+	if r.CreatorVersion != "" {
+		status += " (Creator " + r.CreatorVersion + ")"
+	}
+	fmt.Fprintf(w, "%s: %d line(s), %d stage(s), %d error(s), %d warning(s)\n",
+		status, r.Lines, len(r.Stages), len(r.Errors), len(r.Warnings))
+
+	if len(r.Stages) > 0 {
+		last := r.Stages[len(r.Stages)-1]
+		fmt.Fprintf(w, "📍 Last stage: %s (log line %d)\n", last.Name, last.LogLine)
+	}
+	writeProblems(w, "❌", r.Errors, max)
+	writeProblems(w, "⚠️", r.Warnings, max)
+
+	if !r.Success && len(r.Errors) == 0 && len(r.Tail) > 0 {
+		fmt.Fprintln(w, "📜 No error recognized; last lines of the log:")
+		for _, line := range r.Tail {
+			fmt.Fprintln(w, "   ", line)
+		}
+	}
+}
+
+func writeProblems(w io.Writer, icon string, problems []Problem, max int) {
+	shown := problems
+	if max > 0 && len(shown) > max {
+		shown = shown[:max]
+	}
+	for _, p := range shown {
+		fmt.Fprintf(w, "%s [%s] %s (log line %d)\n", icon, p.Kind, p.String(), p.LogLine)
+	}
+	if hidden := len(problems) - len(shown); hidden > 0 {
+		fmt.Fprintf(w, "   … and %d more %s(s)\n", hidden, problems[0].Severity)
+	}
+}
+
+// WriteJSON atomically writes the result as indented JSON to path.
+func (r *Result) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cocoslog: %w", err)
+	}
+	if err := atomicfile.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("cocoslog: %w", err)
+	}
+	return nil
+}
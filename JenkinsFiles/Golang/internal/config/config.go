@@ -0,0 +1,203 @@
+// Package config resolves the paths the build tools work on. Every setting
+// can come from a command-line flag, an environment variable or a JSON
+// config file, in that order of precedence, before falling back to the
+// tool's default:
+//
+//	{
+//	  "creatorPath": "/Applications/Cocos/Creator/3.8.2/CocosCreator.app/Contents/MacOS/CocosCreator",
+//	  "cocosProject": "cocosProject",
+//	  "unityProject": "UnityBuild",
+//	  "workspaceDir": "XcodeWorkspace"
+//	}
+//
+// Relative paths are resolved against baseDir.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileEnv names the config file when -config is not given.
+const FileEnv = "JENKINS_CONFIG"
+
+// DefaultFile is looked up in baseDir when neither -config nor
+// $JENKINS_CONFIG is set.
+const DefaultFile = "jenkins-config.json"
+
+// Config holds the resolved paths.
+type Config struct {
+	// BaseDir is the folder relative paths are resolved against.
+	BaseDir string `json:"baseDir"`
+	// Product is the game's product name. It picks the workspace and
+	// Xcode project among several, e.g. when folders of a previous game
+	// were left behind.
+	Product string `json:"product"`
+	// CreatorPath is the Cocos Creator executable.
+	CreatorPath string `json:"creatorPath"`
+	// CocosProject is the Cocos Creator project folder.
+	CocosProject string `json:"cocosProject"`
+	// CocosVersion forces the Creator version ("cocos2", "cocos3" or e.g.
+	// "2.4.11"); empty means the one in the project metadata.
+	CocosVersion string `json:"cocosVersion"`
+	// CocosXcodeDir is the folder holding the generated Cocos .xcodeproj.
+	CocosXcodeDir string `json:"cocosXcodeDir"`
+	// CocosXcodeProj is the Cocos .xcodeproj name or path; empty means the
+	// only one in CocosXcodeDir.
+	CocosXcodeProj string `json:"cocosXcodeproj"`
+	// UnityProject is the Unity iOS export folder.
+	UnityProject string `json:"unityProject"`
+	// UnityXcodeProj is the Unity .xcodeproj name or path; empty means the
+	// only one in UnityProject.
+	UnityXcodeProj string `json:"unityXcodeproj"`
+	// HeaderVisibility lists, comma-separated, pattern=visibility rules
+	// for the headers of UnityFramework, e.g.
+	// "Libraries/Plugins/iOS/**/*.h=public". The last matching rule wins.
+	HeaderVisibility string `json:"headerVisibility"`
+	// AssetSets lists, comma-separated, the sets of the Unity
+	// Images.xcassets carried over to the Cocos one, by path inside the
+	// catalog, e.g. "AppIcon.appiconset,LaunchImage.launchimage".
+	AssetSets string `json:"assetSets"`
+	// WorkspaceDir is the folder holding the .xcworkspace.
+	WorkspaceDir string `json:"workspaceDir"`
+	// Workspace is the .xcworkspace name or path; empty means the only one
+	// under WorkspaceDir, or the one named after Product.
+	Workspace string `json:"workspace"`
+}
+
+// setting describes how one field is named on each source.
+type setting struct {
+	flag, env, usage string
+	value            func(c *Config) *string
+	// path marks settings resolved against BaseDir. The .xcodeproj and
+	// .xcworkspace names are resolved by discovery instead.
+	path bool
+}
+
+var settings = []setting{
+	{"base-dir", "JENKINS_BASE_DIR", "folder relative paths are resolved against", func(c *Config) *string { return &c.BaseDir }, false},
+	{"product", "JENKINS_PRODUCT", "game product name, picks the workspace and Xcode project when there are several", func(c *Config) *string { return &c.Product }, false},
+	{"creator", "COCOS_CREATOR_PATH", "Cocos Creator executable", func(c *Config) *string { return &c.CreatorPath }, true},
+	{"cocos-project", "COCOS_PROJECT_DIR", "Cocos Creator project folder", func(c *Config) *string { return &c.CocosProject }, true},
+	{"cocos-version", "COCOS_VERSION", "Cocos Creator version: cocos2, cocos3 or x.y.z (default: from the project)", func(c *Config) *string { return &c.CocosVersion }, false},
+	{"cocos-xcode-dir", "COCOS_XCODE_DIR", "folder holding the Cocos .xcodeproj", func(c *Config) *string { return &c.CocosXcodeDir }, true},
+	{"cocos-xcodeproj", "COCOS_XCODEPROJ", "Cocos .xcodeproj name or path (default: the only one found)", func(c *Config) *string { return &c.CocosXcodeProj }, false},
+	{"unity-project", "UNITY_PROJECT_DIR", "Unity iOS export folder", func(c *Config) *string { return &c.UnityProject }, true},
+	{"unity-xcodeproj", "UNITY_XCODEPROJ", "Unity .xcodeproj name or path (default: the only one found)", func(c *Config) *string { return &c.UnityXcodeProj }, false},
+	{"header-visibility", "UNITY_HEADER_VISIBILITY", "comma-separated pattern=public|private|project rules for UnityFramework headers, last match wins", func(c *Config) *string { return &c.HeaderVisibility }, false},
+	{"asset-sets", "XCODE_ASSET_SETS", "comma-separated Images.xcassets sets to copy from Unity to Cocos", func(c *Config) *string { return &c.AssetSets }, false},
+	{"workspace-dir", "XCODE_WORKSPACE_DIR", "folder holding the .xcworkspace", func(c *Config) *string { return &c.WorkspaceDir }, true},
+	{"workspace", "XCODE_WORKSPACE", ".xcworkspace name or path (default: the only one found, or the one named after the product)", func(c *Config) *string { return &c.Workspace }, false},
+}
+
+// Flags are the command-line overrides registered by Bind.
+type Flags struct {
+	file   string
+	values Config
+}
+
+// Bind registers -config and one flag per setting on fs.
+func Bind(fs *flag.FlagSet) *Flags {
+	f := &Flags{}
+	fs.StringVar(&f.file, "config", "", "JSON config file (default: $"+FileEnv+" or "+DefaultFile+" in the base dir)")
+	for _, s := range settings {
+		fs.StringVar(s.value(&f.values), s.flag, "", s.usage+" (env "+s.env+")")
+	}
+	return f
+}
+
+// Resolve layers the config file, the environment and the flags over
+// defaults and makes every path absolute. A relative BaseDir is taken
+// relative to the working directory.
+func (f *Flags) Resolve(defaults Config) (*Config, error) {
+	c := defaults
+	env := Config{}
+	for _, s := range settings {
+		*s.value(&env) = os.Getenv(s.env)
+	}
+
+	// The config file may itself set baseDir, so it is found with the
+	// base dir known before reading it.
+	base := firstNonEmpty(f.values.BaseDir, env.BaseDir, c.BaseDir)
+	file, explicit := firstNonEmpty(f.file, os.Getenv(FileEnv)), true
+	if file == "" {
+		file, explicit = filepath.Join(base, DefaultFile), false
+	}
+	fromFile, err := readFile(file)
+	if err != nil && (explicit || !errors.Is(err, os.ErrNotExist)) {
+		return nil, err
+	}
+
+	for _, layer := range []*Config{fromFile, &env, &f.values} {
+		if layer == nil {
+			continue
+		}
+		for _, s := range settings {
+			if v := *s.value(layer); v != "" {
+				*s.value(&c) = v
+			}
+		}
+	}
+
+	if c.BaseDir, err = filepath.Abs(c.BaseDir); err != nil {
+		return nil, err
+	}
+	for _, s := range settings {
+		if p := s.value(&c); s.path && *p != "" {
+			*p = c.Resolve(*p)
+		}
+	}
+	return &c, nil
+}
+
+// Resolve returns path made absolute against BaseDir.
+func (c *Config) Resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.BaseDir, path)
+}
+
+// String lists the resolved settings, one per line, for logs.
+func (c *Config) String() string {
+	var b strings.Builder
+	for _, s := range settings {
+		if v := *s.value(c); v != "" {
+			fmt.Fprintf(&b, "%s: %s\n", s.flag, v)
+		}
+	}
+	return b.String()
+}
+
+func readFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	// A relative baseDir in the file is relative to the file itself.
+	if c.BaseDir != "" && !filepath.IsAbs(c.BaseDir) {
+		c.BaseDir = filepath.Join(filepath.Dir(path), c.BaseDir)
+	}
+	return &c, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
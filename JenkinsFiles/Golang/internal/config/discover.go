@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Discovery is the project or workspace picked among the candidates found
+// in a folder, with why it was picked, so tools can report their choice.
+type Discovery struct {
+	Path string
+	// Candidates are all the paths found, sorted; empty when the path was
+	// given explicitly.
+	Candidates []string
+	// Reason says how Path was chosen.
+	Reason string
+	// Mismatch is set when Path is the only candidate but is not named
+	// after the expected product, e.g. a workspace left by another game.
+	Mismatch bool
+}
+
+func (d *Discovery) String() string {
+	return d.Path + " (" + d.Reason + ")"
+}
+
+// FindXcodeProj returns the .xcodeproj named name in dir. name may also be
+// a path, with or without the .xcodeproj extension. When name is empty the
+// only .xcodeproj directly inside dir is used.
+func FindXcodeProj(dir, name string) (string, error) {
+	d, err := DiscoverXcodeProj(dir, name, "")
+	if err != nil {
+		return "", err
+	}
+	return d.Path, nil
+}
+
+// DiscoverXcodeProj picks the .xcodeproj named name in dir, like
+// FindXcodeProj. Without a name, several projects directly inside dir are
+// told apart by the expected product name.
+func DiscoverXcodeProj(dir, name, product string) (*Discovery, error) {
+	return discover(dir, ".xcodeproj", name, product, func() ([]string, error) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		var found []string
+		for _, e := range entries {
+			if e.IsDir() && strings.HasSuffix(e.Name(), ".xcodeproj") {
+				found = append(found, filepath.Join(dir, e.Name()))
+			}
+		}
+		return found, nil
+	})
+}
+
+// DiscoverWorkspace picks the .xcworkspace named name under dir. Without a
+// name every workspace under dir is a candidate, except the ones embedded
+// in .xcodeproj bundles, and several are told apart by the expected product
+// name.
+func DiscoverWorkspace(dir, name, product string) (*Discovery, error) {
+	return discover(dir, ".xcworkspace", name, product, func() ([]string, error) {
+		var found []string
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				return nil
+			}
+			switch filepath.Ext(path) {
+			case ".xcworkspace":
+				found = append(found, path)
+				return filepath.SkipDir
+			case ".xcodeproj":
+				return filepath.SkipDir
+			}
+			return nil
+		})
+		return found, err
+	})
+}
+
+// discover implements the discovery rules shared by projects and
+// workspaces: an explicit name wins, then the only candidate, then the only
+// candidate named after product.
+func discover(dir, ext, name, product string, list func() ([]string, error)) (*Discovery, error) {
+	if name != "" {
+		if !strings.HasSuffix(name, ext) {
+			name += ext
+		}
+		path := name
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, name)
+		}
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("no %s at %s", ext, path)
+		}
+		return &Discovery{Path: path, Reason: "chosen explicitly"}, nil
+	}
+
+	found, err := list()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(found)
+	if len(found) == 0 {
+		return nil, fmt.Errorf("no %s found in %s", ext, dir)
+	}
+
+	var matches []string
+	if product != "" {
+		for _, path := range found {
+			if sameName(strings.TrimSuffix(filepath.Base(path), ext), product) {
+				matches = append(matches, path)
+			}
+		}
+	}
+	switch {
+	case len(matches) == 1:
+		reason := fmt.Sprintf("named after product %q", product)
+		if len(found) > 1 {
+			reason += fmt.Sprintf(", among %d candidates: %s", len(found), relList(dir, found))
+		}
+		return &Discovery{Path: matches[0], Candidates: found, Reason: reason}, nil
+	case len(matches) > 1:
+		return nil, fmt.Errorf("several %s in %s are named after product %q (%s); choose one explicitly", ext, dir, product, relList(dir, matches))
+	case len(found) == 1 && product != "":
+		return &Discovery{Path: found[0], Candidates: found, Mismatch: true,
+			Reason: fmt.Sprintf("only one found, but not named after product %q", product)}, nil
+	case len(found) == 1:
+		return &Discovery{Path: found[0], Candidates: found, Reason: "only one found"}, nil
+	case product != "":
+		return nil, fmt.Errorf("%d %s in %s (%s), none named after product %q; choose one explicitly", len(found), ext, dir, relList(dir, found), product)
+	}
+	return nil, fmt.Errorf("several %s in %s (%s); choose one explicitly or set the product name", ext, dir, relList(dir, found))
+}
+
+// sameName compares names the way Creator and Xcode derive them from a
+// product name, ignoring case, spaces and punctuation.
+func sameName(a, b string) bool {
+	return nameKey(a) == nameKey(b)
+}
+
+func nameKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
+
+// relList lists paths relative to dir, for messages.
+func relList(dir string, paths []string) string {
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		if rel, err := filepath.Rel(dir, path); err == nil {
+			path = rel
+		}
+		names[i] = path
+	}
+	return strings.Join(names, ", ")
+}
+
+// PbxprojPath returns the project.pbxproj inside an .xcodeproj.
+func PbxprojPath(xcodeproj string) string {
+	return filepath.Join(xcodeproj, "project.pbxproj")
+}
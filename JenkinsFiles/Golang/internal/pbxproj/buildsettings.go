@@ -0,0 +1,288 @@
+package pbxproj
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AllConfigurations selects every configuration in Configurations.
+const AllConfigurations = "all"
+
+// Inherited is the list entry that pulls in the value from the level below
+// (project settings for a target, xcconfig or defaults for the project).
+const Inherited = "$(inherited)"
+
+// Configurations returns the configurations of t, or of the project when t
+// is nil, whose name is name. An empty name or "all" selects all of them.
+func (p *Project) Configurations(t *PBXNativeTarget, name string) ([]*XCBuildConfiguration, error) {
+	var configs []*XCBuildConfiguration
+	var err error
+	if t == nil {
+		configs, err = p.projectConfigurations()
+	} else {
+		configs, err = p.TargetConfigurations(t)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if name == "" || strings.EqualFold(name, AllConfigurations) {
+		return configs, nil
+	}
+	for _, c := range configs {
+		if c.Name == name {
+			return []*XCBuildConfiguration{c}, nil
+		}
+	}
+	return nil, fmt.Errorf("pbxproj: configuration %q: %w", name, ErrNotFound)
+}
+
+// projectConfigurations returns the project-level configurations.
+func (p *Project) projectConfigurations() ([]*XCBuildConfiguration, error) {
+	root, err := p.Root()
+	if err != nil {
+		return nil, err
+	}
+	list, err := p.ConfigurationList(root.BuildConfigurationList)
+	if err != nil {
+		return nil, err
+	}
+	var configs []*XCBuildConfiguration
+	for _, id := range list.BuildConfigurations {
+		c, err := p.BuildConfiguration(id)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, c)
+	}
+	return configs, nil
+}
+
+// Get returns the raw value of a build setting: a string or a list.
+func (c *XCBuildConfiguration) Get(key string) (interface{}, bool) {
+	v, ok := c.BuildSettings[key]
+	return v, ok
+}
+
+// GetList returns a build setting as a list. A string value is split the
+// way Xcode expands list-typed settings: on whitespace outside quotes, so
+// "$(SRCROOT)/My Libs" stays one entry. Entries keep their quotes, as Xcode
+// writes them in list values too.
+func (c *XCBuildConfiguration) GetList(key string) []string {
+	switch v := c.BuildSettings[key].(type) {
+	case string:
+		return splitList(v)
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			out = append(out, fmt.Sprint(item))
+		}
+		return out
+	case []string:
+		return append([]string(nil), v...)
+	}
+	return nil
+}
+
+// Set stores a build setting; value is a string or a list of strings.
+func (c *XCBuildConfiguration) Set(key string, value interface{}) {
+	if c.BuildSettings == nil {
+		c.BuildSettings = map[string]interface{}{}
+	}
+	c.BuildSettings[key] = value
+}
+
+// Delete removes a build setting and reports whether it was present.
+func (c *XCBuildConfiguration) Delete(key string) bool {
+	if _, ok := c.BuildSettings[key]; !ok {
+		return false
+	}
+	delete(c.BuildSettings, key)
+	return true
+}
+
+// Append adds values missing from a list-valued setting and reports whether
+// anything changed. A setting that did not exist yet starts with
+// $(inherited) so the values add to, rather than replace, the inherited ones.
+func (c *XCBuildConfiguration) Append(key string, values ...string) bool {
+	_, exists := c.BuildSettings[key]
+	list := c.GetList(key)
+	if !exists {
+		list = []string{Inherited}
+	}
+	changed := !exists
+	for _, v := range values {
+		if !containsEntry(list, v) {
+			list = append(list, quoteEntry(v))
+			changed = true
+		}
+	}
+	if changed {
+		c.Set(key, listValue(list))
+	}
+	return changed
+}
+
+// RemoveValues drops values from a list-valued setting and reports whether
+// anything changed. When only $(inherited) is left the setting is deleted,
+// which is equivalent and keeps the project tidy; a list that did not
+// inherit is left empty so inherited values do not reappear.
+func (c *XCBuildConfiguration) RemoveValues(key string, values ...string) bool {
+	if _, ok := c.BuildSettings[key]; !ok {
+		return false
+	}
+	var kept []string
+	for _, v := range c.GetList(key) {
+		if !containsEntry(values, v) {
+			kept = append(kept, v)
+		}
+	}
+	if len(kept) == len(c.GetList(key)) {
+		return false
+	}
+	if len(kept) == 1 && unquoteEntry(kept[0]) == Inherited {
+		return c.Delete(key)
+	}
+	if len(kept) == 0 {
+		c.Set(key, "")
+		return true
+	}
+	c.Set(key, listValue(kept))
+	return true
+}
+
+// splitList splits a list-typed setting into words. Quotes group words and
+// a backslash escapes the next character; both are kept in the words.
+func splitList(s string) []string {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+			continue
+		}
+		word.WriteRune(r)
+		inWord = true
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// unquoteEntry drops the quotes and escapes of a list entry, for comparing
+// "$(SRCROOT)/My Libs" with $(SRCROOT)/My Libs.
+func unquoteEntry(s string) string {
+	var out strings.Builder
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+			continue
+		case quote != 0 && r == quote:
+			quote = 0
+			continue
+		case quote == 0 && (r == '"' || r == '\''):
+			quote = r
+			continue
+		}
+		out.WriteRune(r)
+	}
+	return out.String()
+}
+
+// quoteEntry quotes a value that would otherwise split into several
+// entries, such as a path with a space.
+func quoteEntry(s string) string {
+	if len(splitList(s)) > 1 {
+		return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+	}
+	return s
+}
+
+// containsEntry reports whether list holds s, ignoring how either is quoted.
+func containsEntry(list []string, s string) bool {
+	for _, item := range list {
+		if unquoteEntry(item) == unquoteEntry(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// listValue stores a list the way Xcode does: a single entry as a plain string.
+func listValue(list []string) interface{} {
+	if len(list) == 1 {
+		return list[0]
+	}
+	out := make([]interface{}, len(list))
+	for i, v := range list {
+		out[i] = v
+	}
+	return out
+}
+
+// settingRef matches $(NAME) and ${NAME} references, with an optional
+// modifier such as $(PRODUCT_NAME:rfc1034identifier), which is ignored.
+var settingRef = regexp.MustCompile(`\$(?:\(([A-Za-z0-9_]+)(?::[^)]*)?\)|\{([A-Za-z0-9_]+)(?::[^}]*)?\})`)
+
+// ExpandSetting evaluates a setting the way Xcode does: it is looked up in
+// levels from the most specific (a target configuration) to the least (the
+// project configuration), and $(NAME) references to other settings are
+// substituted. References no level defines are left as they are.
+func ExpandSetting(key string, levels ...*XCBuildConfiguration) (string, bool) {
+	return expandSetting(key, levels, 0)
+}
+
+func expandSetting(key string, levels []*XCBuildConfiguration, depth int) (string, bool) {
+	for _, c := range levels {
+		if c == nil {
+			continue
+		}
+		if _, ok := c.BuildSettings[key]; !ok {
+			continue
+		}
+		value := strings.Join(c.GetList(key), " ")
+		if s, ok := c.BuildSettings[key].(string); ok {
+			value = s
+		}
+		// Guard against settings that refer to each other.
+		if depth >= 8 {
+			return value, true
+		}
+		return settingRef.ReplaceAllStringFunc(value, func(ref string) string {
+			m := settingRef.FindStringSubmatch(ref)
+			name := m[1] + m[2]
+			if name == key {
+				return ref
+			}
+			if v, ok := expandSetting(name, levels, depth+1); ok {
+				return v
+			}
+			return ref
+		}), true
+	}
+	return "", false
+}
@@ -0,0 +1,49 @@
+package pbxproj
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetListKeepsQuotedEntries(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"$(inherited) $(SRCROOT)/Libs", []string{"$(inherited)", "$(SRCROOT)/Libs"}},
+		{`$(inherited) "$(SRCROOT)/My Libs"`, []string{"$(inherited)", `"$(SRCROOT)/My Libs"`}},
+		{`'$(SRCROOT)/My Libs'  -ObjC`, []string{`'$(SRCROOT)/My Libs'`, "-ObjC"}},
+		{`$(SRCROOT)/My\ Libs`, []string{`$(SRCROOT)/My\ Libs`}},
+		{`"DEBUG=1" "NAME=\"a b\""`, []string{`"DEBUG=1"`, `"NAME=\"a b\""`}},
+		{"  ", nil},
+	}
+	for _, tt := range tests {
+		c := &XCBuildConfiguration{BuildSettings: map[string]interface{}{"K": tt.value}}
+		if got := c.GetList("K"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetList(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestAppendAndRemoveQuotedEntries(t *testing.T) {
+	c := &XCBuildConfiguration{BuildSettings: map[string]interface{}{
+		"HEADER_SEARCH_PATHS": `$(inherited) "$(SRCROOT)/My Libs"`,
+	}}
+	if c.Append("HEADER_SEARCH_PATHS", "$(SRCROOT)/My Libs") {
+		t.Error("Append added a path that is already there, quoted")
+	}
+	if !c.Append("HEADER_SEARCH_PATHS", "$(SRCROOT)/More Libs") {
+		t.Fatal("Append did not add a new path")
+	}
+	want := []interface{}{"$(inherited)", `"$(SRCROOT)/My Libs"`, `"$(SRCROOT)/More Libs"`}
+	if got := c.BuildSettings["HEADER_SEARCH_PATHS"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("after Append = %q, want %q", got, want)
+	}
+
+	if !c.RemoveValues("HEADER_SEARCH_PATHS", "$(SRCROOT)/My Libs", `"$(SRCROOT)/More Libs"`) {
+		t.Fatal("RemoveValues did not remove the quoted paths")
+	}
+	if _, ok := c.BuildSettings["HEADER_SEARCH_PATHS"]; ok {
+		t.Errorf("only $(inherited) left, want the setting deleted, got %q", c.BuildSettings["HEADER_SEARCH_PATHS"])
+	}
+}
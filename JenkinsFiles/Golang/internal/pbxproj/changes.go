@@ -0,0 +1,82 @@
+package pbxproj
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Kinds of ObjectChange.
+const (
+	ObjectCreated  = "created"
+	ObjectDeleted  = "deleted"
+	ObjectModified = "modified"
+)
+
+// ObjectChange is one object that differs between two states of the same
+// project. Unlike Diff it works on IDs, so it shows exactly what a patcher
+// is about to write.
+type ObjectChange struct {
+	Kind    string
+	ID      string
+	ISA     string
+	Comment string
+}
+
+func (c ObjectChange) String() string {
+	sign := map[string]string{ObjectCreated: "+", ObjectDeleted: "-", ObjectModified: "~"}[c.Kind]
+	if c.Comment == "" {
+		return fmt.Sprintf("%s %s %s", sign, c.ISA, c.ID)
+	}
+	return fmt.Sprintf("%s %s %s (%s)", sign, c.ISA, c.ID, c.Comment)
+}
+
+// Clone returns an independent copy of p, typically taken before patching
+// so the result can be compared with ObjectChanges.
+func (p *Project) Clone() (*Project, error) {
+	data, err := p.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data, p.Name)
+}
+
+// ObjectChanges lists the objects created, deleted or modified going from
+// before to after, sorted by ISA and ID.
+func ObjectChanges(before, after *Project) []ObjectChange {
+	beforeRaw, afterRaw := before.Raw(), after.Raw()
+	beforeComments := objectComments(beforeRaw, before.Name)
+	afterComments := objectComments(afterRaw, after.Name)
+	beforeObjects := beforeRaw["objects"].(map[string]interface{})
+	afterObjects := afterRaw["objects"].(map[string]interface{})
+
+	var changes []ObjectChange
+	for id, obj := range after.Objects {
+		old, ok := beforeObjects[id]
+		switch {
+		case !ok:
+			changes = append(changes, ObjectChange{ObjectCreated, id, obj.ISA(), afterComments[id]})
+		case !reflect.DeepEqual(old, afterObjects[id]):
+			changes = append(changes, ObjectChange{ObjectModified, id, obj.ISA(), afterComments[id]})
+		}
+	}
+	for id, obj := range before.Objects {
+		if _, ok := after.Objects[id]; !ok {
+			changes = append(changes, ObjectChange{ObjectDeleted, id, obj.ISA(), beforeComments[id]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].ISA != changes[j].ISA {
+			return changes[i].ISA < changes[j].ISA
+		}
+		return changes[i].ID < changes[j].ID
+	})
+	return changes
+}
+
+// objectComments returns the annotation Marshal writes for every object.
+func objectComments(raw map[string]interface{}, projectName string) map[string]string {
+	e := &encoder{objects: raw["objects"].(map[string]interface{}), projectName: projectName}
+	e.buildComments()
+	return e.comments
+}
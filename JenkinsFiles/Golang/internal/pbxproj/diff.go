@@ -0,0 +1,479 @@
+package pbxproj
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// projectScope names the project-level configurations in a diff.
+const projectScope = "(project)"
+
+// ProjectDiff is a semantic comparison of two projects. Objects are matched
+// by name and path rather than ID, so regenerated IDs do not show up.
+type ProjectDiff struct {
+	TargetsAdded   []string     `json:"targetsAdded,omitempty"`
+	TargetsRemoved []string     `json:"targetsRemoved,omitempty"`
+	FilesAdded     []string     `json:"filesAdded,omitempty"`
+	FilesRemoved   []string     `json:"filesRemoved,omitempty"`
+	Moves          []FileMove   `json:"moves,omitempty"`
+	Targets        []TargetDiff `json:"targets,omitempty"`
+}
+
+// TargetDiff lists the changes inside a target present in both projects.
+// The project-level configurations appear under the name "(project)".
+type TargetDiff struct {
+	Name           string       `json:"name"`
+	PhasesAdded    []string     `json:"phasesAdded,omitempty"`
+	PhasesRemoved  []string     `json:"phasesRemoved,omitempty"`
+	Phases         []PhaseDiff  `json:"phases,omitempty"`
+	Configurations []ConfigDiff `json:"configurations,omitempty"`
+}
+
+// PhaseDiff lists the files added to, removed from or re-attributed in a phase.
+type PhaseDiff struct {
+	Phase   string          `json:"phase"`
+	Added   []string        `json:"added,omitempty"`
+	Removed []string        `json:"removed,omitempty"`
+	Changed []SettingChange `json:"changed,omitempty"`
+}
+
+// ConfigDiff lists build-setting changes of one configuration.
+type ConfigDiff struct {
+	Configuration string          `json:"configuration"`
+	Changes       []SettingChange `json:"changes"`
+}
+
+// SettingChange is one changed key; Old or New is nil when the key was
+// added or removed.
+type SettingChange struct {
+	Key string      `json:"key"`
+	Old interface{} `json:"old,omitempty"`
+	New interface{} `json:"new,omitempty"`
+}
+
+// FileMove is a file that left one phase and joined another.
+type FileMove struct {
+	File string `json:"file"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Empty reports whether the projects are semantically identical.
+func (d *ProjectDiff) Empty() bool {
+	return len(d.TargetsAdded) == 0 && len(d.TargetsRemoved) == 0 &&
+		len(d.FilesAdded) == 0 && len(d.FilesRemoved) == 0 &&
+		len(d.Moves) == 0 && len(d.Targets) == 0
+}
+
+// Diff compares a (before) with b (after).
+func Diff(a, b *Project) (*ProjectDiff, error) {
+	d := &ProjectDiff{}
+	// One resolver per project: resolving each path through ParentGroup
+	// would be quadratic on Unity exports.
+	ra, rb := a.PathResolver(), b.PathResolver()
+
+	filesA, filesB := a.fileSet(ra), b.fileSet(rb)
+	d.FilesAdded = setMinus(filesB, filesA)
+	d.FilesRemoved = setMinus(filesA, filesB)
+
+	targetsA, err := a.targetsByName()
+	if err != nil {
+		return nil, err
+	}
+	targetsB, err := b.targetsByName()
+	if err != nil {
+		return nil, err
+	}
+	d.TargetsAdded = setMinus(keySet(targetsB), keySet(targetsA))
+	d.TargetsRemoved = setMinus(keySet(targetsA), keySet(targetsB))
+
+	// Membership of every file across all phases, for move detection.
+	removedFrom := map[string][]location{}
+	addedTo := map[string][]location{}
+
+	var names []string
+	for name := range targetsA {
+		if _, ok := targetsB[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		td, err := diffTarget(a, b, ra, rb, targetsA[name], targetsB[name])
+		if err != nil {
+			return nil, err
+		}
+		for _, pd := range td.Phases {
+			where := location{target: name, phase: pd.Phase}
+			for _, f := range pd.Removed {
+				removedFrom[f] = append(removedFrom[f], where)
+			}
+			for _, f := range pd.Added {
+				addedTo[f] = append(addedTo[f], where)
+			}
+		}
+		d.Targets = append(d.Targets, td)
+	}
+
+	projectConfigs, err := diffConfigurations(a.projectConfigurations, b.projectConfigurations)
+	if err != nil {
+		return nil, err
+	}
+	if len(projectConfigs) > 0 {
+		d.Targets = append(d.Targets, TargetDiff{Name: projectScope, Configurations: projectConfigs})
+	}
+
+	// A file dropped from exactly one phase and added to exactly one other is
+	// reported as a move instead of an unrelated add and remove.
+	for _, f := range sortedKeysOf(removedFrom) {
+		if len(removedFrom[f]) != 1 || len(addedTo[f]) != 1 {
+			continue
+		}
+		from, to := removedFrom[f][0], addedTo[f][0]
+		d.Moves = append(d.Moves, FileMove{File: f, From: from.String(), To: to.String()})
+		d.dropPhaseEntry(from, f, false)
+		d.dropPhaseEntry(to, f, true)
+	}
+	d.pruneTargets()
+	return d, nil
+}
+
+// WriteText writes a human-readable report of the diff.
+func (d *ProjectDiff) WriteText(w io.Writer) {
+	if d.Empty() {
+		fmt.Fprintln(w, "No semantic differences.")
+		return
+	}
+	for _, t := range d.TargetsAdded {
+		fmt.Fprintf(w, "+ target %q\n", t)
+	}
+	for _, t := range d.TargetsRemoved {
+		fmt.Fprintf(w, "- target %q\n", t)
+	}
+	for _, f := range d.FilesAdded {
+		fmt.Fprintf(w, "+ file %s\n", f)
+	}
+	for _, f := range d.FilesRemoved {
+		fmt.Fprintf(w, "- file %s\n", f)
+	}
+	for _, m := range d.Moves {
+		fmt.Fprintf(w, "> %s moved from %s to %s\n", m.File, m.From, m.To)
+	}
+	for _, t := range d.Targets {
+		fmt.Fprintf(w, "target %q:\n", t.Name)
+		for _, p := range t.PhasesAdded {
+			fmt.Fprintf(w, "  + phase %q\n", p)
+		}
+		for _, p := range t.PhasesRemoved {
+			fmt.Fprintf(w, "  - phase %q\n", p)
+		}
+		for _, p := range t.Phases {
+			fmt.Fprintf(w, "  phase %q:\n", p.Phase)
+			for _, f := range p.Added {
+				fmt.Fprintf(w, "    + %s\n", f)
+			}
+			for _, f := range p.Removed {
+				fmt.Fprintf(w, "    - %s\n", f)
+			}
+			for _, c := range p.Changed {
+				writeChange(w, "    ", c)
+			}
+		}
+		for _, c := range t.Configurations {
+			fmt.Fprintf(w, "  configuration %q:\n", c.Configuration)
+			for _, ch := range c.Changes {
+				writeChange(w, "    ", ch)
+			}
+		}
+	}
+}
+
+func writeChange(w io.Writer, indent string, c SettingChange) {
+	switch {
+	case c.Old == nil:
+		fmt.Fprintf(w, "%s+ %s = %s\n", indent, c.Key, formatSetting(c.New))
+	case c.New == nil:
+		fmt.Fprintf(w, "%s- %s = %s\n", indent, c.Key, formatSetting(c.Old))
+	default:
+		fmt.Fprintf(w, "%s~ %s: %s -> %s\n", indent, c.Key, formatSetting(c.Old), formatSetting(c.New))
+	}
+}
+
+func formatSetting(v interface{}) string {
+	switch val := v.(type) {
+	case []interface{}:
+		parts := make([]string, len(val))
+		for i, item := range val {
+			parts[i] = formatSetting(item)
+		}
+		return "(" + strings.Join(parts, ", ") + ")"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = k + " = " + formatSetting(val[k])
+		}
+		return "{" + strings.Join(parts, "; ") + "}"
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func diffTarget(a, b *Project, ra, rb *PathResolver, ta, tb *PBXNativeTarget) (TargetDiff, error) {
+	td := TargetDiff{Name: ta.Name}
+
+	phasesA, err := a.namedPhases(ta)
+	if err != nil {
+		return td, err
+	}
+	phasesB, err := b.namedPhases(tb)
+	if err != nil {
+		return td, err
+	}
+	td.PhasesAdded = setMinus(keySet(phasesB), keySet(phasesA))
+	td.PhasesRemoved = setMinus(keySet(phasesA), keySet(phasesB))
+
+	for _, name := range sortedKeysOf(phasesA) {
+		pb, ok := phasesB[name]
+		if !ok {
+			continue
+		}
+		filesA, filesB := a.phaseMembers(ra, phasesA[name]), b.phaseMembers(rb, pb)
+		pd := PhaseDiff{
+			Phase:   name,
+			Added:   setMinus(keySet(filesB), keySet(filesA)),
+			Removed: setMinus(keySet(filesA), keySet(filesB)),
+		}
+		for _, f := range sortedKeysOf(filesA) {
+			after, ok := filesB[f]
+			if ok && !reflect.DeepEqual(filesA[f], after) {
+				pd.Changed = append(pd.Changed, SettingChange{Key: f, Old: orNil(filesA[f]), New: orNil(after)})
+			}
+		}
+		if len(pd.Added) > 0 || len(pd.Removed) > 0 || len(pd.Changed) > 0 {
+			td.Phases = append(td.Phases, pd)
+		}
+	}
+
+	configs, err := diffConfigurations(
+		func() ([]*XCBuildConfiguration, error) { return a.TargetConfigurations(ta) },
+		func() ([]*XCBuildConfiguration, error) { return b.TargetConfigurations(tb) })
+	if err != nil {
+		return td, err
+	}
+	td.Configurations = configs
+	return td, nil
+}
+
+func diffConfigurations(listA, listB func() ([]*XCBuildConfiguration, error)) ([]ConfigDiff, error) {
+	configsA, err := listA()
+	if err != nil {
+		return nil, err
+	}
+	configsB, err := listB()
+	if err != nil {
+		return nil, err
+	}
+	byName := func(configs []*XCBuildConfiguration) map[string]map[string]interface{} {
+		m := map[string]map[string]interface{}{}
+		for _, c := range configs {
+			m[c.Name] = c.BuildSettings
+		}
+		return m
+	}
+	settingsA, settingsB := byName(configsA), byName(configsB)
+
+	var diffs []ConfigDiff
+	names := keySet(settingsA)
+	for name := range settingsB {
+		names[name] = true
+	}
+	for _, name := range sortedKeysOf(names) {
+		before, after := settingsA[name], settingsB[name]
+		keys := keySet(before)
+		for k := range after {
+			keys[k] = true
+		}
+		var changes []SettingChange
+		for _, k := range sortedKeysOf(keys) {
+			if !reflect.DeepEqual(before[k], after[k]) {
+				changes = append(changes, SettingChange{Key: k, Old: before[k], New: after[k]})
+			}
+		}
+		if len(changes) > 0 {
+			diffs = append(diffs, ConfigDiff{Configuration: name, Changes: changes})
+		}
+	}
+	return diffs, nil
+}
+
+func (p *Project) targetsByName() (map[string]*PBXNativeTarget, error) {
+	targets, err := p.NativeTargets()
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]*PBXNativeTarget, len(targets))
+	for _, t := range targets {
+		m[t.Name] = t
+	}
+	return m, nil
+}
+
+// namedPhases keys the phases of t by display name, numbering repeats.
+func (p *Project) namedPhases(t *PBXNativeTarget) (map[string]*BuildPhase, error) {
+	phases, err := p.TargetBuildPhases(t)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]*BuildPhase{}
+	for _, phase := range phases {
+		name := phase.DisplayName()
+		for n := 2; m[name] != nil; n++ {
+			name = fmt.Sprintf("%s #%d", phase.DisplayName(), n)
+		}
+		m[name] = phase
+	}
+	return m, nil
+}
+
+// phaseMembers maps the files of phase, by display path, to their settings.
+func (p *Project) phaseMembers(r *PathResolver, phase *BuildPhase) map[string]map[string]interface{} {
+	m := map[string]map[string]interface{}{}
+	for _, id := range phase.Files {
+		bf, ok := p.Objects[id].(*PBXBuildFile)
+		if !ok {
+			m["<missing "+id+">"] = nil
+			continue
+		}
+		m[p.filePath(r, bf)] = bf.Settings
+	}
+	return m
+}
+
+// filePath identifies the file behind a build file independently of IDs.
+func (p *Project) filePath(r *PathResolver, bf *PBXBuildFile) string {
+	switch ref := p.Objects[bf.FileRef].(type) {
+	case *PBXFileReference:
+		return r.ResolvedPath(ref)
+	case *PBXGroup:
+		return ref.DisplayName()
+	case *GenericObject:
+		if path, ok := ref.Fields["path"].(string); ok {
+			return path
+		}
+	}
+	if dep, ok := p.Objects[stringValue(bf.Extra["productRef"])].(*GenericObject); ok {
+		return "package:" + stringValue(dep.Fields["productName"])
+	}
+	return "<unresolved " + bf.FileRef + ">"
+}
+
+// fileSet lists every file reference by resolved path.
+func (p *Project) fileSet(r *PathResolver) map[string]bool {
+	set := map[string]bool{}
+	for _, obj := range p.Objects {
+		if ref, ok := obj.(*PBXFileReference); ok {
+			set[r.ResolvedPath(ref)] = true
+		}
+	}
+	return set
+}
+
+func (d *ProjectDiff) dropPhaseEntry(where location, file string, added bool) {
+	for i := range d.Targets {
+		if d.Targets[i].Name != where.target {
+			continue
+		}
+		for j := range d.Targets[i].Phases {
+			pd := &d.Targets[i].Phases[j]
+			if pd.Phase != where.phase {
+				continue
+			}
+			if added {
+				pd.Added = without(pd.Added, file)
+			} else {
+				pd.Removed = without(pd.Removed, file)
+			}
+		}
+	}
+}
+
+// pruneTargets drops phase and target entries left empty after move detection.
+func (d *ProjectDiff) pruneTargets() {
+	var targets []TargetDiff
+	for _, t := range d.Targets {
+		var phases []PhaseDiff
+		for _, p := range t.Phases {
+			if len(p.Added) > 0 || len(p.Removed) > 0 || len(p.Changed) > 0 {
+				phases = append(phases, p)
+			}
+		}
+		t.Phases = phases
+		if len(t.PhasesAdded) > 0 || len(t.PhasesRemoved) > 0 || len(t.Phases) > 0 || len(t.Configurations) > 0 {
+			targets = append(targets, t)
+		}
+	}
+	d.Targets = targets
+}
+
+// location is a phase of a target.
+type location struct {
+	target string
+	phase  string
+}
+
+func (l location) String() string {
+	return l.target + "/" + l.phase
+}
+
+func without(list []string, s string) []string {
+	var out []string
+	for _, item := range list {
+		if item != s {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func orNil(m map[string]interface{}) interface{} {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+func setMinus(a, b map[string]bool) []string {
+	var out []string
+	for _, k := range sortedKeysOf(a) {
+		if !b[k] {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+func keySet[V interface{}](m map[string]V) map[string]bool {
+	set := make(map[string]bool, len(m))
+	for k := range m {
+		set[k] = true
+	}
+	return set
+}
+
+func sortedKeysOf[V interface{}](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,103 @@
+package pbxproj
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// goldenVariant returns the golden project with its IDs regenerated and the
+// given text replacements applied.
+func goldenVariant(t *testing.T, replacements ...string) *Project {
+	t.Helper()
+	p, err := Parse(editGolden(t, append([]string{"E5C1A0012B0D4E5F", "7A9B00112B0D4E5F"}, replacements...)...), "Golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestDiffIgnoresIDs(t *testing.T) {
+	before, err := Load(goldenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := Diff(before, goldenVariant(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Empty() {
+		var buf bytes.Buffer
+		d.WriteText(&buf)
+		t.Errorf("regenerated IDs reported as changes:\n%s", buf.String())
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before, err := Load(goldenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := goldenVariant(t,
+		// AppDelegate.swift leaves Sources for Resources.
+		"\t\t\t\t7A9B00112B0D4E5F00000101 /* AppDelegate.swift in Sources */,\n", "",
+		"\t\t\t\t7A9B00112B0D4E5F00000103 /* Assets.xcassets in Resources */,\n",
+		"\t\t\t\t7A9B00112B0D4E5F00000101 /* AppDelegate.swift in Sources */,\n\t\t\t\t7A9B00112B0D4E5F00000103 /* Assets.xcassets in Resources */,\n",
+		// Both Golden configurations, and the project's Debug one.
+		"MARKETING_VERSION = 1.0;", "MARKETING_VERSION = 1.1;",
+		"DEBUG_INFORMATION_FORMAT = dwarf;", "DEBUG_INFORMATION_FORMAT = \"dwarf-with-dsym\";",
+	)
+	d, err := Diff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantMoves := []FileMove{{File: "Golden/AppDelegate.swift", From: "Golden/Sources", To: "Golden/Resources"}}
+	if !reflect.DeepEqual(d.Moves, wantMoves) {
+		t.Errorf("Moves = %+v, want %+v", d.Moves, wantMoves)
+	}
+	if len(d.FilesAdded) > 0 || len(d.FilesRemoved) > 0 || len(d.TargetsAdded) > 0 || len(d.TargetsRemoved) > 0 {
+		t.Errorf("unexpected file or target changes: %+v", d)
+	}
+
+	version := []SettingChange{{Key: "MARKETING_VERSION", Old: "1.0", New: "1.1"}}
+	wantTargets := []TargetDiff{
+		// The move is reported once, not again as a phase add and remove.
+		{Name: "Golden", Configurations: []ConfigDiff{
+			{Configuration: "Debug", Changes: version},
+			{Configuration: "Release", Changes: version},
+		}},
+		{Name: projectScope, Configurations: []ConfigDiff{
+			{Configuration: "Debug", Changes: []SettingChange{{Key: "DEBUG_INFORMATION_FORMAT", Old: "dwarf", New: "dwarf-with-dsym"}}},
+		}},
+	}
+	if !reflect.DeepEqual(d.Targets, wantTargets) {
+		t.Errorf("Targets = %+v\nwant %+v", d.Targets, wantTargets)
+	}
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	var want map[string]interface{}
+	json.Unmarshal([]byte(`{
+		"moves": [{"file": "Golden/AppDelegate.swift", "from": "Golden/Sources", "to": "Golden/Resources"}],
+		"targets": [
+			{"name": "Golden", "configurations": [
+				{"configuration": "Debug", "changes": [{"key": "MARKETING_VERSION", "old": "1.0", "new": "1.1"}]},
+				{"configuration": "Release", "changes": [{"key": "MARKETING_VERSION", "old": "1.0", "new": "1.1"}]}
+			]},
+			{"name": "(project)", "configurations": [
+				{"configuration": "Debug", "changes": [{"key": "DEBUG_INFORMATION_FORMAT", "old": "dwarf", "new": "dwarf-with-dsym"}]}
+			]}
+		]
+	}`), &want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("JSON = %s", data)
+	}
+}
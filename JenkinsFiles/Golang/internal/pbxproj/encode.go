@@ -0,0 +1,414 @@
+// Package pbxproj reads and writes Xcode project.pbxproj files.
+package pbxproj
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/atomicfile"
+)
+
+// singleLineISAs are written by Xcode on one line inside their section.
+var singleLineISAs = map[string]bool{
+	"PBXBuildFile":     true,
+	"PBXFileReference": true,
+}
+
+// defaultPhaseNames are the comments Xcode uses for build phases without a name.
+var defaultPhaseNames = map[string]string{
+	"PBXSourcesBuildPhase":     "Sources",
+	"PBXFrameworksBuildPhase":  "Frameworks",
+	"PBXResourcesBuildPhase":   "Resources",
+	"PBXHeadersBuildPhase":     "Headers",
+	"PBXCopyFilesBuildPhase":   "CopyFiles",
+	"PBXShellScriptBuildPhase": "ShellScript",
+	"PBXRezBuildPhase":         "Rez",
+	"PBXAppleScriptBuildPhase": "AppleScript",
+}
+
+// unannotatedKeys hold object IDs that Xcode writes without a trailing comment.
+var unannotatedKeys = map[string]bool{
+	"remoteGlobalIDString": true,
+	"TestTargetID":         true,
+}
+
+// fileElementISAs are annotated with their name, or the last component of
+// their path.
+var fileElementISAs = map[string]bool{
+	"PBXFileReference":  true,
+	"PBXGroup":          true,
+	"PBXVariantGroup":   true,
+	"XCVersionGroup":    true,
+	"PBXReferenceProxy": true,
+}
+
+// Marshal serializes a decoded project tree (as produced by plist.Unmarshal)
+// in the OpenStep dialect Xcode writes, including section markers and object
+// comments. projectName is the .xcodeproj name without extension; Xcode uses it
+// in the comment of the project's configuration list.
+func Marshal(project map[string]interface{}, projectName string) ([]byte, error) {
+	objects, ok := project["objects"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("pbxproj: missing objects dictionary")
+	}
+	e := &encoder{objects: objects, projectName: projectName}
+	e.buildComments()
+
+	e.buf.WriteString("// !$*UTF8*$!\n{\n")
+	for _, key := range sortedKeys(project) {
+		e.indent(1)
+		e.writeString(key)
+		e.buf.WriteString(" = ")
+		if key == "objects" {
+			if err := e.writeObjects(); err != nil {
+				return nil, err
+			}
+		} else if err := e.writeValue(project[key], key, 1, false); err != nil {
+			return nil, err
+		}
+		e.buf.WriteString(";\n")
+	}
+	e.buf.WriteString("}\n")
+	return e.buf.Bytes(), nil
+}
+
+// WriteFile serializes project to path, deriving the project name from the
+// enclosing .xcodeproj directory. The file is replaced atomically.
+func WriteFile(path string, project map[string]interface{}) error {
+	data, err := Marshal(project, ProjectName(path))
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, data, 0644)
+}
+
+// ProjectName returns the name of the .xcodeproj bundle containing path.
+func ProjectName(path string) string {
+	dir := filepath.Base(filepath.Dir(path))
+	return strings.TrimSuffix(dir, ".xcodeproj")
+}
+
+type encoder struct {
+	buf         bytes.Buffer
+	objects     map[string]interface{}
+	projectName string
+	comments    map[string]string
+}
+
+// buildComments computes the annotation Xcode writes next to every object ID.
+func (e *encoder) buildComments() {
+	e.comments = make(map[string]string, len(e.objects))
+
+	// Build files are annotated with the phase that contains them, and
+	// configuration lists with the project or target that owns them.
+	phaseOf := map[string]string{}
+	ownerOf := map[string]string{}
+	for id, obj := range e.objects {
+		m, ok := obj.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if files, ok := m["files"].([]interface{}); ok && strings.HasSuffix(stringValue(m["isa"]), "BuildPhase") {
+			for _, f := range files {
+				if fid, ok := f.(string); ok {
+					phaseOf[fid] = id
+				}
+			}
+		}
+		if list, ok := m["buildConfigurationList"].(string); ok {
+			ownerOf[list] = id
+		}
+	}
+
+	for id, obj := range e.objects {
+		m, ok := obj.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		e.comments[id] = e.objectComment(m, ownerOf[id])
+	}
+	// Build file comments depend on the names resolved above.
+	for id, obj := range e.objects {
+		m, ok := obj.(map[string]interface{})
+		if !ok || stringValue(m["isa"]) != "PBXBuildFile" {
+			continue
+		}
+		e.comments[id] = e.buildFileComment(m, phaseOf[id])
+	}
+}
+
+func (e *encoder) objectComment(m map[string]interface{}, ownerID string) string {
+	isa := stringValue(m["isa"])
+	switch isa {
+	case "PBXProject":
+		return "Project object"
+	case "PBXContainerItemProxy", "PBXTargetDependency":
+		return isa
+	case "XCConfigurationList":
+		owner, _ := e.objects[ownerID].(map[string]interface{})
+		if owner == nil {
+			return ""
+		}
+		ownerISA := stringValue(owner["isa"])
+		name := stringValue(owner["name"])
+		if ownerISA == "PBXProject" {
+			name = e.projectName
+		}
+		return fmt.Sprintf("Build configuration list for %s \"%s\"", ownerISA, name)
+	case "XCRemoteSwiftPackageReference":
+		repo := strings.TrimSuffix(stringValue(m["repositoryURL"]), ".git")
+		return fmt.Sprintf("XCRemoteSwiftPackageReference \"%s\"", filepath.Base(repo))
+	case "XCLocalSwiftPackageReference":
+		return fmt.Sprintf("XCLocalSwiftPackageReference \"%s\"", stringValue(m["relativePath"]))
+	case "XCSwiftPackageProductDependency":
+		return stringValue(m["productName"])
+	}
+	if name := stringValue(m["name"]); name != "" {
+		return name
+	}
+	if def, ok := defaultPhaseNames[isa]; ok {
+		return def
+	}
+	if p := stringValue(m["path"]); p != "" && fileElementISAs[isa] {
+		return path.Base(p)
+	}
+	return stringValue(m["path"])
+}
+
+func (e *encoder) buildFileComment(m map[string]interface{}, phaseID string) string {
+	ref := stringValue(m["fileRef"])
+	if ref == "" {
+		ref = stringValue(m["productRef"])
+	}
+	name := e.comments[ref]
+	if phase := e.comments[phaseID]; phase != "" {
+		return fmt.Sprintf("%s in %s", name, phase)
+	}
+	return name
+}
+
+// writeObjects writes the objects dictionary grouped into isa sections.
+func (e *encoder) writeObjects() error {
+	sections := map[string][]string{}
+	for id, obj := range e.objects {
+		m, ok := obj.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("pbxproj: object %s is not a dictionary", id)
+		}
+		isa := stringValue(m["isa"])
+		if isa == "" {
+			return fmt.Errorf("pbxproj: object %s has no isa", id)
+		}
+		sections[isa] = append(sections[isa], id)
+	}
+	isas := make([]string, 0, len(sections))
+	for isa := range sections {
+		isas = append(isas, isa)
+	}
+	sort.Strings(isas)
+
+	e.buf.WriteString("{\n")
+	for _, isa := range isas {
+		ids := sections[isa]
+		sort.Strings(ids)
+		fmt.Fprintf(&e.buf, "\n/* Begin %s section */\n", isa)
+		singleLine := singleLineISAs[isa]
+		for _, id := range ids {
+			e.indent(2)
+			e.writeString(id)
+			e.writeComment(id)
+			e.buf.WriteString(" = ")
+			if err := e.writeDict(e.objects[id].(map[string]interface{}), 2, singleLine); err != nil {
+				return fmt.Errorf("pbxproj: object %s: %w", id, err)
+			}
+			e.buf.WriteString(";\n")
+		}
+		fmt.Fprintf(&e.buf, "/* End %s section */\n", isa)
+	}
+	e.indent(1)
+	e.buf.WriteString("}")
+	return nil
+}
+
+func (e *encoder) writeValue(v interface{}, key string, depth int, singleLine bool) error {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return e.writeDict(val, depth, singleLine)
+	case map[string]string:
+		m := make(map[string]interface{}, len(val))
+		for k, s := range val {
+			m[k] = s
+		}
+		return e.writeDict(m, depth, singleLine)
+	case []interface{}:
+		return e.writeArray(val, key, depth, singleLine)
+	case []string:
+		items := make([]interface{}, len(val))
+		for i, s := range val {
+			items[i] = s
+		}
+		return e.writeArray(items, key, depth, singleLine)
+	case string:
+		e.writeString(val)
+		if !unannotatedKeys[key] {
+			e.writeComment(val)
+		}
+	case int:
+		e.buf.WriteString(strconv.Itoa(val))
+	case int64:
+		e.buf.WriteString(strconv.FormatInt(val, 10))
+	case uint64:
+		e.buf.WriteString(strconv.FormatUint(val, 10))
+	case float64:
+		e.buf.WriteString(strconv.FormatFloat(val, 'f', -1, 64))
+	case bool:
+		if val {
+			e.buf.WriteString("YES")
+		} else {
+			e.buf.WriteString("NO")
+		}
+	default:
+		return fmt.Errorf("unsupported value type %T for key %q", v, key)
+	}
+	return nil
+}
+
+func (e *encoder) writeDict(m map[string]interface{}, depth int, singleLine bool) error {
+	e.buf.WriteString("{")
+	if !singleLine {
+		e.buf.WriteString("\n")
+	}
+	for _, key := range sortedKeys(m) {
+		if !singleLine {
+			e.indent(depth + 1)
+		}
+		e.writeString(key)
+		e.buf.WriteString(" = ")
+		if err := e.writeValue(m[key], key, depth+1, singleLine); err != nil {
+			return err
+		}
+		e.buf.WriteString(";")
+		if singleLine {
+			e.buf.WriteString(" ")
+		} else {
+			e.buf.WriteString("\n")
+		}
+	}
+	if !singleLine {
+		e.indent(depth)
+	}
+	e.buf.WriteString("}")
+	return nil
+}
+
+func (e *encoder) writeArray(items []interface{}, key string, depth int, singleLine bool) error {
+	e.buf.WriteString("(")
+	if !singleLine {
+		e.buf.WriteString("\n")
+	}
+	for _, item := range items {
+		if !singleLine {
+			e.indent(depth + 1)
+		}
+		if err := e.writeValue(item, key, depth+1, singleLine); err != nil {
+			return err
+		}
+		e.buf.WriteString(",")
+		if singleLine {
+			e.buf.WriteString(" ")
+		} else {
+			e.buf.WriteString("\n")
+		}
+	}
+	if !singleLine {
+		e.indent(depth)
+	}
+	e.buf.WriteString(")")
+	return nil
+}
+
+// writeComment appends the Xcode annotation for s when it is an object ID.
+func (e *encoder) writeComment(s string) {
+	if _, ok := e.objects[s]; !ok {
+		return
+	}
+	if c := e.comments[s]; c != "" {
+		fmt.Fprintf(&e.buf, " /* %s */", c)
+	}
+}
+
+func (e *encoder) writeString(s string) {
+	if !needsQuotes(s) {
+		e.buf.WriteString(s)
+		return
+	}
+	e.buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '\\':
+			e.buf.WriteString(`\\`)
+		case '"':
+			e.buf.WriteString(`\"`)
+		case '\n':
+			e.buf.WriteString(`\n`)
+		case '\t':
+			e.buf.WriteString(`\t`)
+		case '\r':
+			e.buf.WriteString(`\r`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&e.buf, `\U%04x`, r)
+			} else {
+				e.buf.WriteRune(r)
+			}
+		}
+	}
+	e.buf.WriteByte('"')
+}
+
+func (e *encoder) indent(depth int) {
+	for i := 0; i < depth; i++ {
+		e.buf.WriteByte('\t')
+	}
+}
+
+// needsQuotes reports whether Xcode would quote s.
+func needsQuotes(s string) bool {
+	if s == "" || strings.Contains(s, "//") || strings.Contains(s, "___") {
+		return true
+	}
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '_', r == '$', r == '/', r == '.':
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	// Xcode always writes isa first, then the remaining keys in order.
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i] == "isa" || keys[j] == "isa" {
+			return keys[i] == "isa"
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+func stringValue(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
@@ -0,0 +1,79 @@
+package pbxproj
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"howett.net/plist"
+)
+
+const goldenPath = "testdata/Golden.xcodeproj/project.pbxproj"
+
+// editGolden returns the golden project text with each old, new pair of
+// replacements applied to every occurrence.
+func editGolden(t *testing.T, replacements ...string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	for i := 0; i < len(replacements); i += 2 {
+		if !strings.Contains(text, replacements[i]) {
+			t.Fatalf("golden project has no %q", replacements[i])
+		}
+		text = strings.ReplaceAll(text, replacements[i], replacements[i+1])
+	}
+	return []byte(text)
+}
+
+// diffLines reports the first line where got and want differ.
+func diffLines(t *testing.T, got, want []byte) {
+	t.Helper()
+	g, w := strings.Split(string(got), "\n"), strings.Split(string(want), "\n")
+	for i := 0; i < len(g) || i < len(w); i++ {
+		var gl, wl string
+		if i < len(g) {
+			gl = g[i]
+		}
+		if i < len(w) {
+			wl = w[i]
+		}
+		if gl != wl {
+			t.Fatalf("line %d:\n got: %q\nwant: %q", i+1, gl, wl)
+		}
+	}
+}
+
+// TestGoldenRoundTrip writes an Xcode-written project back unchanged, both
+// through the typed objects and straight from the decoded plist.
+func TestGoldenRoundTrip(t *testing.T) {
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Load(goldenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "Golden" {
+		t.Errorf("Name = %q, want Golden", p.Name)
+	}
+	got, err := p.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	diffLines(t, got, want)
+
+	var raw map[string]interface{}
+	if _, err := plist.Unmarshal(want, &raw); err != nil {
+		t.Fatal(err)
+	}
+	got, err = Marshal(raw, "Golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	diffLines(t, got, want)
+}
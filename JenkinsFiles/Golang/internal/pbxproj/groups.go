@@ -0,0 +1,374 @@
+package pbxproj
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Source trees Xcode resolves file reference paths against.
+const (
+	SourceTreeGroup         = "<group>"
+	SourceTreeAbsolute      = "<absolute>"
+	SourceTreeSourceRoot    = "SOURCE_ROOT"
+	SourceTreeBuiltProducts = "BUILT_PRODUCTS_DIR"
+	SourceTreeSDKRoot       = "SDKROOT"
+)
+
+// fileTypes maps extensions to the lastKnownFileType Xcode assigns.
+var fileTypes = map[string]string{
+	".a":           "archive.ar",
+	".bundle":      "wrapper.plug-in",
+	".c":           "sourcecode.c.c",
+	".cpp":         "sourcecode.cpp.cpp",
+	".framework":   "wrapper.framework",
+	".h":           "sourcecode.c.h",
+	".json":        "text.json",
+	".m":           "sourcecode.c.objc",
+	".mm":          "sourcecode.cpp.objcpp",
+	".plist":       "text.plist.xml",
+	".png":         "image.png",
+	".storyboard":  "file.storyboard",
+	".swift":       "sourcecode.swift",
+	".xcassets":    "folder.assetcatalog",
+	".xcconfig":    "text.xcconfig",
+	".xcframework": "wrapper.xcframework",
+	".xcprivacy":   "text.xml",
+}
+
+// FileTypeForPath guesses the lastKnownFileType of path from its extension;
+// paths without a known extension are treated as folders.
+func FileTypeForPath(p string) string {
+	if t, ok := fileTypes[strings.ToLower(path.Ext(p))]; ok {
+		return t
+	}
+	if path.Ext(p) == "" {
+		return "folder"
+	}
+	return "file"
+}
+
+// FileSpec describes a file reference to add to the project.
+type FileSpec struct {
+	// Path is relative to SOURCE_ROOT for <group> and SOURCE_ROOT references
+	// and relative to the source tree otherwise.
+	Path       string
+	SourceTree string
+	// FileType is the explicitFileType when Explicit is set, otherwise the
+	// lastKnownFileType. Empty means FileTypeForPath.
+	FileType string
+	Explicit bool
+}
+
+// MainGroup returns the root group of the project navigator.
+func (p *Project) MainGroup() (*PBXGroup, error) {
+	root, err := p.Root()
+	if err != nil {
+		return nil, err
+	}
+	return p.Group(root.MainGroup)
+}
+
+// ParentGroup returns the group listing id among its children.
+func (p *Project) ParentGroup(id string) (*PBXGroup, error) {
+	for _, gid := range p.IDs() {
+		g, ok := p.Objects[gid].(*PBXGroup)
+		if !ok {
+			continue
+		}
+		for _, child := range g.Children {
+			if child == id {
+				return g, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("pbxproj: parent group of %s: %w", id, ErrNotFound)
+}
+
+// GroupByPath returns the group reached from the main group by following
+// slash-separated display names, e.g. "Classes/UI". An empty path is the
+// main group.
+func (p *Project) GroupByPath(groupPath string) (*PBXGroup, error) {
+	g, err := p.MainGroup()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range splitGroupPath(groupPath) {
+		child := p.childGroup(g, name)
+		if child == nil {
+			return nil, fmt.Errorf("pbxproj: group %q: %w", groupPath, ErrNotFound)
+		}
+		g = child
+	}
+	return g, nil
+}
+
+// EnsureGroupPath is GroupByPath but creates missing groups along the way.
+// New groups are virtual (name only, <group> source tree).
+func (p *Project) EnsureGroupPath(groupPath string) (*PBXGroup, error) {
+	g, err := p.MainGroup()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range splitGroupPath(groupPath) {
+		child := p.childGroup(g, name)
+		if child == nil {
+			child = &PBXGroup{Class: "PBXGroup", Name: name, SourceTree: SourceTreeGroup}
+			p.AddNew(child, g.ID(), name)
+			g.Children = append(g.Children, child.ID())
+		}
+		g = child
+	}
+	return g, nil
+}
+
+// GroupDir returns the directory of g relative to SOURCE_ROOT, following
+// the paths of its <group>-relative ancestors.
+func (p *Project) GroupDir(g *PBXGroup) string {
+	return groupDir(g, p.parentOf)
+}
+
+// ResolvedPath returns where ref points, relative to SOURCE_ROOT for
+// <group> and SOURCE_ROOT references and prefixed with $(TREE) otherwise.
+// Each call searches the project for parent groups; use a PathResolver to
+// resolve many references.
+func (p *Project) ResolvedPath(ref *PBXFileReference) string {
+	return resolvedPath(ref, p.parentOf)
+}
+
+// PathResolver resolves the paths of many references against one snapshot
+// of the group tree. It must not be used after groups change.
+type PathResolver struct {
+	parents map[string]*PBXGroup
+}
+
+// PathResolver indexes the group tree of p.
+func (p *Project) PathResolver() *PathResolver {
+	parents := map[string]*PBXGroup{}
+	for _, id := range p.IDs() {
+		g, ok := p.Objects[id].(*PBXGroup)
+		if !ok {
+			continue
+		}
+		for _, child := range g.Children {
+			// Like ParentGroup, the first group listing a child wins.
+			if _, ok := parents[child]; !ok {
+				parents[child] = g
+			}
+		}
+	}
+	return &PathResolver{parents: parents}
+}
+
+// ResolvedPath is Project.ResolvedPath.
+func (r *PathResolver) ResolvedPath(ref *PBXFileReference) string {
+	return resolvedPath(ref, r.parentOf)
+}
+
+func (r *PathResolver) parentOf(id string) *PBXGroup {
+	return r.parents[id]
+}
+
+func (p *Project) parentOf(id string) *PBXGroup {
+	g, err := p.ParentGroup(id)
+	if err != nil {
+		return nil
+	}
+	return g
+}
+
+func groupDir(g *PBXGroup, parentOf func(id string) *PBXGroup) string {
+	var parts []string
+	for cur := g; ; {
+		if cur.Path != "" {
+			parts = append([]string{cur.Path}, parts...)
+		}
+		if cur.SourceTree != SourceTreeGroup {
+			break
+		}
+		parent := parentOf(cur.ID())
+		if parent == nil {
+			// Only the main group has no parent; it sits at SOURCE_ROOT.
+			break
+		}
+		cur = parent
+	}
+	return path.Clean(path.Join(append([]string{"."}, parts...)...))
+}
+
+func resolvedPath(ref *PBXFileReference, parentOf func(id string) *PBXGroup) string {
+	switch ref.SourceTree {
+	case SourceTreeSourceRoot:
+		return path.Clean(ref.Path)
+	case SourceTreeGroup:
+		parent := parentOf(ref.ID())
+		if parent == nil {
+			return path.Clean(ref.Path)
+		}
+		return path.Join(groupDir(parent, parentOf), ref.Path)
+	case SourceTreeAbsolute:
+		return ref.Path
+	default:
+		return "$(" + ref.SourceTree + ")/" + ref.Path
+	}
+}
+
+// AddFileReference returns the reference for spec, creating it if needed,
+// and makes sure it is listed in the group at groupPath (created on demand).
+// An existing reference already attached to another group is left there.
+func (p *Project) AddFileReference(groupPath string, spec FileSpec) (*PBXFileReference, error) {
+	group, err := p.EnsureGroupPath(groupPath)
+	if err != nil {
+		return nil, err
+	}
+	tree := spec.SourceTree
+	if tree == "" {
+		tree = SourceTreeGroup
+	}
+	want := path.Clean(spec.Path)
+	if tree != SourceTreeSourceRoot && tree != SourceTreeGroup && tree != SourceTreeAbsolute {
+		want = "$(" + tree + ")/" + spec.Path
+	}
+
+	resolver := p.PathResolver()
+	for _, id := range p.IDs() {
+		ref, ok := p.Objects[id].(*PBXFileReference)
+		if !ok || resolver.ResolvedPath(ref) != want {
+			continue
+		}
+		if _, err := p.ParentGroup(id); err != nil {
+			group.Children = append(group.Children, id)
+		}
+		return ref, nil
+	}
+
+	refPath := spec.Path
+	if tree == SourceTreeGroup {
+		refPath = relativePath(p.GroupDir(group), spec.Path)
+	}
+	ref := &PBXFileReference{Path: refPath, SourceTree: tree}
+	if base := path.Base(refPath); base != refPath {
+		ref.Name = base
+	}
+	fileType := spec.FileType
+	if fileType == "" {
+		fileType = FileTypeForPath(spec.Path)
+	}
+	if spec.Explicit {
+		ref.ExplicitFileType = fileType
+	} else {
+		ref.LastKnownFileType = fileType
+	}
+	p.AddNew(ref, tree, spec.Path)
+	group.Children = append(group.Children, ref.ID())
+	return ref, nil
+}
+
+// FolderFileType is the lastKnownFileType of a folder reference, which Xcode
+// copies into the product as a whole.
+const FolderFileType = "folder"
+
+// EnsureFolderReference returns the folder reference to dir, relative to
+// SOURCE_ROOT, that belongs in the group at groupPath, and describes what it
+// changed to get there. Only references relative to SOURCE_ROOT or to that
+// group qualify: a folder with the same path listed in another group, such
+// as a plugin's own Data, is not it. A qualifying reference left outside
+// any group is attached to the group; none at all creates one. The reference
+// is typed by lastKnownFileType, as Xcode does for folders; an
+// explicitFileType would make it a file of that type.
+func (p *Project) EnsureFolderReference(groupPath, dir string) (*PBXFileReference, []string, error) {
+	group, err := p.EnsureGroupPath(groupPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	want := path.Clean(dir)
+	resolver := p.PathResolver()
+	var matches []*PBXFileReference
+	for _, id := range p.IDs() {
+		ref, ok := p.Objects[id].(*PBXFileReference)
+		if !ok || (ref.SourceTree != SourceTreeSourceRoot && ref.SourceTree != SourceTreeGroup) {
+			continue
+		}
+		if resolver.ResolvedPath(ref) != want {
+			continue
+		}
+		if parent := resolver.parentOf(id); parent == nil || parent == group {
+			matches = append(matches, ref)
+		}
+	}
+
+	var changes []string
+	var ref *PBXFileReference
+	switch len(matches) {
+	case 0:
+		ref = &PBXFileReference{Path: want, SourceTree: SourceTreeSourceRoot, LastKnownFileType: FolderFileType}
+		if base := path.Base(want); base != want {
+			ref.Name = base
+		}
+		p.AddNew(ref, SourceTreeSourceRoot, want)
+		group.Children = append(group.Children, ref.ID())
+		return ref, []string{"created folder reference " + want}, nil
+	case 1:
+		ref = matches[0]
+	default:
+		ids := make([]string, len(matches))
+		for i, m := range matches {
+			ids[i] = m.ID()
+		}
+		return nil, nil, fmt.Errorf("pbxproj: %d references to the folder %s: %s", len(matches), want, strings.Join(ids, ", "))
+	}
+
+	if resolver.parentOf(ref.ID()) == nil {
+		group.Children = append(group.Children, ref.ID())
+		name := "the main group"
+		if group.DisplayName() != "" {
+			name = fmt.Sprintf("group %q", group.DisplayName())
+		}
+		changes = append(changes, fmt.Sprintf("attached %s to %s", want, name))
+	}
+	if ref.LastKnownFileType != FolderFileType || ref.ExplicitFileType != "" {
+		changes = append(changes, fmt.Sprintf("made %s a folder reference (explicitFileType %q, lastKnownFileType %q)", want, ref.ExplicitFileType, ref.LastKnownFileType))
+		ref.ExplicitFileType = ""
+		ref.LastKnownFileType = FolderFileType
+	}
+	return ref, changes, nil
+}
+
+func (p *Project) childGroup(g *PBXGroup, name string) *PBXGroup {
+	for _, id := range g.Children {
+		if child, ok := p.Objects[id].(*PBXGroup); ok && child.Class == "PBXGroup" && child.DisplayName() == name {
+			return child
+		}
+	}
+	return nil
+}
+
+func splitGroupPath(groupPath string) []string {
+	var parts []string
+	for _, part := range strings.Split(groupPath, "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// relativePath returns target relative to dir, both relative to the same root.
+func relativePath(dir, target string) string {
+	dir, target = path.Clean(dir), path.Clean(target)
+	if dir == "." {
+		return target
+	}
+	dirParts := strings.Split(dir, "/")
+	targetParts := strings.Split(target, "/")
+	i := 0
+	for i < len(dirParts) && i < len(targetParts) && dirParts[i] == targetParts[i] {
+		i++
+	}
+	var out []string
+	for range dirParts[i:] {
+		out = append(out, "..")
+	}
+	out = append(out, targetParts[i:]...)
+	return path.Join(out...)
+}
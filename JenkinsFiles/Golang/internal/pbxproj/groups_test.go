@@ -0,0 +1,110 @@
+package pbxproj
+
+import "testing"
+
+func TestMatchPath(t *testing.T) {
+	for _, c := range []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"Libraries/Plugins/iOS/*.h", "Libraries/Plugins/iOS/A.h", true},
+		{"Libraries/Plugins/iOS/*.h", "Libraries/Plugins/iOS/Sub/A.h", false},
+		{"Libraries/Plugins/iOS/**/*.h", "Libraries/Plugins/iOS/A.h", true},
+		{"Libraries/Plugins/iOS/**/*.h", "Libraries/Plugins/iOS/Sub/Deep/A.h", true},
+		{"Libraries/Plugins/iOS/**/*.h", "Libraries/Plugins/Android/A.h", false},
+		{"**/Data", "Data", true},
+		{"**", "any/thing", true},
+		{"*.h", "Libraries/A.h", false},
+	} {
+		if got := MatchPath(c.pattern, c.name); got != c.want {
+			t.Errorf("MatchPath(%q, %q) = %v, want %v", c.pattern, c.name, got, c.want)
+		}
+	}
+}
+
+func TestPathResolverMatchesResolvedPath(t *testing.T) {
+	p := &Project{Objects: map[string]Object{}}
+	main := &PBXGroup{Class: "PBXGroup", SourceTree: SourceTreeGroup}
+	libs := &PBXGroup{Class: "PBXGroup", Path: "Libraries", SourceTree: SourceTreeGroup}
+	virtual := &PBXGroup{Class: "PBXGroup", Name: "Virtual", SourceTree: SourceTreeGroup}
+	rooted := &PBXGroup{Class: "PBXGroup", Path: "Classes", SourceTree: SourceTreeSourceRoot}
+	refs := []*PBXFileReference{
+		{Path: "A.h", SourceTree: SourceTreeGroup},
+		{Path: "B.h", SourceTree: SourceTreeGroup},
+		{Path: "C.mm", SourceTree: SourceTreeGroup},
+		{Path: "Data", SourceTree: SourceTreeSourceRoot},
+		{Path: "UIKit.framework", SourceTree: SourceTreeSDKRoot},
+		{Path: "Orphan.h", SourceTree: SourceTreeGroup},
+	}
+	for i, obj := range []Object{main, libs, virtual, rooted} {
+		p.Add(string(rune('a'+i)), obj)
+	}
+	for i, ref := range refs {
+		p.Add(string(rune('A'+i)), ref)
+	}
+	main.Children = []string{libs.ID(), rooted.ID(), refs[3].ID(), refs[4].ID()}
+	libs.Children = []string{refs[0].ID(), virtual.ID()}
+	virtual.Children = []string{refs[1].ID()}
+	rooted.Children = []string{refs[2].ID()}
+
+	want := []string{"Libraries/A.h", "Libraries/B.h", "Classes/C.mm", "Data", "$(SDKROOT)/UIKit.framework", "Orphan.h"}
+	resolver := p.PathResolver()
+	for i, ref := range refs {
+		if got := p.ResolvedPath(ref); got != want[i] {
+			t.Errorf("ResolvedPath(%s) = %q, want %q", ref.Path, got, want[i])
+		}
+		if got := resolver.ResolvedPath(ref); got != want[i] {
+			t.Errorf("PathResolver.ResolvedPath(%s) = %q, want %q", ref.Path, got, want[i])
+		}
+	}
+}
+
+func TestEnsureFolderReference(t *testing.T) {
+	p, err := Parse([]byte(`// !$*UTF8*$!
+{
+	objects = {
+		F1 = {isa = PBXFileReference; explicitFileType = folder; path = Data; sourceTree = SOURCE_ROOT; };
+		F2 = {isa = PBXFileReference; lastKnownFileType = folder; path = Data; sourceTree = "<group>"; };
+		G0 = {isa = PBXGroup; children = (G1, ); sourceTree = "<group>"; };
+		G1 = {isa = PBXGroup; children = (F2, ); path = Plugin; sourceTree = "<group>"; };
+		PR = {isa = PBXProject; mainGroup = G0; targets = ( ); };
+	};
+	rootObject = PR;
+}
+`), "Test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// F1 is in no group: it is attached and retyped; F2 is Plugin/Data.
+	ref, changes, err := p.EnsureFolderReference("", "Data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.ID() != "F1" || len(changes) != 2 {
+		t.Fatalf("got %s, changes %q; want F1 attached and retyped", ref.ID(), changes)
+	}
+	if main, _ := p.MainGroup(); main.Children[len(main.Children)-1] != "F1" {
+		t.Errorf("main group children = %v", main.Children)
+	}
+	if ref.ExplicitFileType != "" || ref.LastKnownFileType != FolderFileType {
+		t.Errorf("F1 = %+v", ref)
+	}
+	if _, changes, _ := p.EnsureFolderReference("", "Data"); len(changes) != 0 {
+		t.Errorf("second call changed %q", changes)
+	}
+
+	// Two candidates is an error, not a guess.
+	dup := &PBXFileReference{Path: "Data", SourceTree: SourceTreeGroup}
+	p.Add("F3", dup)
+	main, _ := p.MainGroup()
+	main.Children = append(main.Children, "F3")
+	if _, _, err := p.EnsureFolderReference("", "Data"); err == nil {
+		t.Error("no error with two Data references in the main group")
+	}
+
+	// The plugin's folder is found in its own group.
+	if ref, _, err := p.EnsureFolderReference("Plugin", "Plugin/Data"); err != nil || ref.ID() != "F2" {
+		t.Errorf("Plugin/Data = %v, %v; want F2", ref, err)
+	}
+}
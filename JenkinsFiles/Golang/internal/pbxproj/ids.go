@@ -0,0 +1,37 @@
+package pbxproj
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// GenerateID derives a 24-character uppercase hex ID, the shape Xcode uses,
+// from the isa and semantic identity of a new object (for example the owning
+// target, the phase and the file it points at). Patching the same input twice
+// therefore yields the same IDs. If the ID is already taken by another object
+// a counter is mixed in until a free one is found, which stays stable because
+// the existing objects are the same on every run.
+func (p *Project) GenerateID(isa string, identity ...string) string {
+	key := isa + "\x00" + strings.Join(identity, "\x00")
+	for attempt := 0; ; attempt++ {
+		seed := key
+		if attempt > 0 {
+			seed += "\x00" + strconv.Itoa(attempt)
+		}
+		sum := sha256.Sum256([]byte(seed))
+		id := strings.ToUpper(hex.EncodeToString(sum[:12]))
+		if _, taken := p.Objects[id]; !taken {
+			return id
+		}
+	}
+}
+
+// AddNew stores obj under an ID generated from its isa and identity and
+// returns that ID.
+func (p *Project) AddNew(obj Object, identity ...string) string {
+	id := p.GenerateID(obj.ISA(), identity...)
+	p.Add(id, obj)
+	return id
+}
@@ -0,0 +1,56 @@
+package pbxproj
+
+import (
+	"regexp"
+	"testing"
+)
+
+var idPattern = regexp.MustCompile(`^[0-9A-F]{24}$`)
+
+func TestGenerateIDIsStable(t *testing.T) {
+	a, b := goldenVariant(t), goldenVariant(t)
+	id := a.GenerateID("PBXBuildFile", "target", "phase", "file")
+	if !idPattern.MatchString(id) {
+		t.Fatalf("ID %q is not 24 uppercase hex digits", id)
+	}
+	if got := b.GenerateID("PBXBuildFile", "target", "phase", "file"); got != id {
+		t.Errorf("same identity gave %s and %s", id, got)
+	}
+	// Fixed across runs and releases, so re-patching a project already
+	// patched by an older build reuses its objects.
+	if want := "52A924CD5D20BACB36BF20EE"; id != want {
+		t.Errorf("ID = %s, want %s", id, want)
+	}
+	for _, other := range [][]string{
+		{"PBXBuildFile", "target", "phase", "other"},
+		{"PBXFileReference", "target", "phase", "file"},
+		{"PBXBuildFile", "target", "phasefile"},
+	} {
+		if got := a.GenerateID(other[0], other[1:]...); got == id {
+			t.Errorf("GenerateID(%q) = %s, the ID of another identity", other, got)
+		}
+	}
+}
+
+func TestGenerateIDAvoidsCollisions(t *testing.T) {
+	a, b := goldenVariant(t), goldenVariant(t)
+	first := a.GenerateID("PBXGroup", "main", "Extra")
+	for _, p := range []*Project{a, b} {
+		p.Add(first, &PBXGroup{Class: "PBXGroup", Name: "Taken", SourceTree: SourceTreeGroup})
+	}
+
+	id := a.GenerateID("PBXGroup", "main", "Extra")
+	if id == first || !idPattern.MatchString(id) {
+		t.Fatalf("colliding identity gave %q, want a new ID other than %s", id, first)
+	}
+	if got := b.GenerateID("PBXGroup", "main", "Extra"); got != id {
+		t.Errorf("colliding identity gave %s and %s", id, got)
+	}
+
+	// AddNew with the same identity twice collides with itself.
+	g1 := &PBXGroup{Class: "PBXGroup", Name: "Extra", SourceTree: SourceTreeGroup}
+	g2 := &PBXGroup{Class: "PBXGroup", Name: "Extra", SourceTree: SourceTreeGroup}
+	if id1, id2 := a.AddNew(g1, "main", "Extra"), a.AddNew(g2, "main", "Extra"); id1 != id || id1 == id2 {
+		t.Errorf("AddNew gave %s then %s, want %s then a new ID", id1, id2, id)
+	}
+}
@@ -0,0 +1,490 @@
+package pbxproj
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// DstSubfolderFrameworks is the dstSubfolderSpec of an Embed Frameworks phase.
+const DstSubfolderFrameworks = 10
+
+// Object is an entry of the project's objects dictionary.
+type Object interface {
+	ID() string
+	ISA() string
+
+	setID(id string)
+	encode() map[string]interface{}
+}
+
+type objectID struct{ id string }
+
+// ID returns the object's key in the objects dictionary.
+func (o *objectID) ID() string { return o.id }
+
+func (o *objectID) setID(id string) { o.id = id }
+
+// PBXProject is the root object of a project.
+type PBXProject struct {
+	objectID
+	Attributes             map[string]interface{}
+	BuildConfigurationList string
+	MainGroup              string
+	ProductRefGroup        string
+	ProjectDirPath         string
+	ProjectRoot            string
+	Targets                []string
+	Extra                  map[string]interface{}
+}
+
+// ISA implements Object.
+func (*PBXProject) ISA() string { return "PBXProject" }
+
+// PBXNativeTarget is a target building a product from sources.
+type PBXNativeTarget struct {
+	objectID
+	BuildConfigurationList string
+	BuildPhases            []string
+	BuildRules             []string
+	Dependencies           []string
+	Name                   string
+	ProductName            string
+	ProductReference       string
+	ProductType            string
+	Extra                  map[string]interface{}
+}
+
+// ISA implements Object.
+func (*PBXNativeTarget) ISA() string { return "PBXNativeTarget" }
+
+// PBXBuildFile is the membership of a file reference in a build phase.
+type PBXBuildFile struct {
+	objectID
+	FileRef  string
+	Settings map[string]interface{}
+	Extra    map[string]interface{}
+}
+
+// ISA implements Object.
+func (*PBXBuildFile) ISA() string { return "PBXBuildFile" }
+
+// PBXFileReference points at a file or folder on disk.
+type PBXFileReference struct {
+	objectID
+	ExplicitFileType  string
+	LastKnownFileType string
+	Name              string
+	Path              string
+	SourceTree        string
+	Extra             map[string]interface{}
+}
+
+// ISA implements Object.
+func (*PBXFileReference) ISA() string { return "PBXFileReference" }
+
+// DisplayName returns the name Xcode shows for the reference.
+func (f *PBXFileReference) DisplayName() string {
+	if f.Name != "" {
+		return f.Name
+	}
+	return f.Path
+}
+
+// PBXGroup is a folder in Xcode's project navigator. Class distinguishes
+// plain groups from PBXVariantGroup.
+type PBXGroup struct {
+	objectID
+	Class      string
+	Children   []string
+	Name       string
+	Path       string
+	SourceTree string
+	Extra      map[string]interface{}
+}
+
+// ISA implements Object.
+func (g *PBXGroup) ISA() string { return g.Class }
+
+// DisplayName returns the name Xcode shows for the group.
+func (g *PBXGroup) DisplayName() string {
+	if g.Name != "" {
+		return g.Name
+	}
+	return g.Path
+}
+
+// BuildPhase is any of the PBX*BuildPhase objects; Class holds the isa.
+// DstPath and DstSubfolderSpec are only meaningful for PBXCopyFilesBuildPhase.
+type BuildPhase struct {
+	objectID
+	Class                              string
+	BuildActionMask                    int
+	DstPath                            string
+	DstSubfolderSpec                   int
+	Files                              []string
+	Name                               string
+	RunOnlyForDeploymentPostprocessing int
+	Extra                              map[string]interface{}
+}
+
+// ISA implements Object.
+func (p *BuildPhase) ISA() string { return p.Class }
+
+// DisplayName returns the name Xcode shows for the phase.
+func (p *BuildPhase) DisplayName() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return defaultPhaseNames[p.Class]
+}
+
+// IsEmbedFrameworks reports whether the phase copies into the Frameworks folder.
+func (p *BuildPhase) IsEmbedFrameworks() bool {
+	return p.Class == "PBXCopyFilesBuildPhase" && p.DstSubfolderSpec == DstSubfolderFrameworks
+}
+
+// XCBuildConfiguration holds the build settings of one configuration.
+type XCBuildConfiguration struct {
+	objectID
+	BaseConfigurationReference string
+	BuildSettings              map[string]interface{}
+	Name                       string
+	Extra                      map[string]interface{}
+}
+
+// ISA implements Object.
+func (*XCBuildConfiguration) ISA() string { return "XCBuildConfiguration" }
+
+// XCConfigurationList lists the configurations of a project or target.
+type XCConfigurationList struct {
+	objectID
+	BuildConfigurations           []string
+	DefaultConfigurationIsVisible int
+	DefaultConfigurationName      string
+	Extra                         map[string]interface{}
+}
+
+// ISA implements Object.
+func (*XCConfigurationList) ISA() string { return "XCConfigurationList" }
+
+// GenericObject keeps objects of any isa without a typed model as-is.
+type GenericObject struct {
+	objectID
+	Class  string
+	Fields map[string]interface{}
+}
+
+// ISA implements Object.
+func (g *GenericObject) ISA() string { return g.Class }
+
+func decodeObject(id string, raw map[string]interface{}) (Object, error) {
+	d := &decoder{id: id, raw: make(map[string]interface{}, len(raw))}
+	for k, v := range raw {
+		d.raw[k] = v
+	}
+	isa := d.string("isa")
+	if isa == "" {
+		return nil, fmt.Errorf("pbxproj: object %s has no isa", id)
+	}
+
+	var obj Object
+	switch {
+	case isa == "PBXProject":
+		obj = &PBXProject{
+			Attributes:             d.dict("attributes"),
+			BuildConfigurationList: d.string("buildConfigurationList"),
+			MainGroup:              d.string("mainGroup"),
+			ProductRefGroup:        d.string("productRefGroup"),
+			ProjectDirPath:         d.string("projectDirPath"),
+			ProjectRoot:            d.string("projectRoot"),
+			Targets:                d.strings("targets"),
+			Extra:                  d.rest(),
+		}
+	case isa == "PBXNativeTarget":
+		obj = &PBXNativeTarget{
+			BuildConfigurationList: d.string("buildConfigurationList"),
+			BuildPhases:            d.strings("buildPhases"),
+			BuildRules:             d.strings("buildRules"),
+			Dependencies:           d.strings("dependencies"),
+			Name:                   d.string("name"),
+			ProductName:            d.string("productName"),
+			ProductReference:       d.string("productReference"),
+			ProductType:            d.string("productType"),
+			Extra:                  d.rest(),
+		}
+	case isa == "PBXBuildFile":
+		obj = &PBXBuildFile{
+			FileRef:  d.string("fileRef"),
+			Settings: d.dict("settings"),
+			Extra:    d.rest(),
+		}
+	case isa == "PBXFileReference":
+		obj = &PBXFileReference{
+			ExplicitFileType:  d.string("explicitFileType"),
+			LastKnownFileType: d.string("lastKnownFileType"),
+			Name:              d.string("name"),
+			Path:              d.string("path"),
+			SourceTree:        d.string("sourceTree"),
+			Extra:             d.rest(),
+		}
+	case isa == "PBXGroup" || isa == "PBXVariantGroup":
+		obj = &PBXGroup{
+			Class:      isa,
+			Children:   d.strings("children"),
+			Name:       d.string("name"),
+			Path:       d.string("path"),
+			SourceTree: d.string("sourceTree"),
+			Extra:      d.rest(),
+		}
+	case isBuildPhase(isa):
+		obj = &BuildPhase{
+			Class:                              isa,
+			BuildActionMask:                    d.int("buildActionMask"),
+			DstPath:                            d.string("dstPath"),
+			DstSubfolderSpec:                   d.int("dstSubfolderSpec"),
+			Files:                              d.strings("files"),
+			Name:                               d.string("name"),
+			RunOnlyForDeploymentPostprocessing: d.int("runOnlyForDeploymentPostprocessing"),
+			Extra:                              d.rest(),
+		}
+	case isa == "XCBuildConfiguration":
+		obj = &XCBuildConfiguration{
+			BaseConfigurationReference: d.string("baseConfigurationReference"),
+			BuildSettings:              d.dict("buildSettings"),
+			Name:                       d.string("name"),
+			Extra:                      d.rest(),
+		}
+	case isa == "XCConfigurationList":
+		obj = &XCConfigurationList{
+			BuildConfigurations:           d.strings("buildConfigurations"),
+			DefaultConfigurationIsVisible: d.int("defaultConfigurationIsVisible"),
+			DefaultConfigurationName:      d.string("defaultConfigurationName"),
+			Extra:                         d.rest(),
+		}
+	default:
+		obj = &GenericObject{Class: isa, Fields: d.rest()}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	obj.setID(id)
+	return obj, nil
+}
+
+func isBuildPhase(isa string) bool {
+	_, ok := defaultPhaseNames[isa]
+	return ok
+}
+
+func (p *PBXProject) encode() map[string]interface{} {
+	e := newEncoding(p.ISA(), p.Extra)
+	e.optDict("attributes", p.Attributes)
+	e.set("buildConfigurationList", p.BuildConfigurationList)
+	e.set("mainGroup", p.MainGroup)
+	e.optString("productRefGroup", p.ProductRefGroup)
+	e.set("projectDirPath", p.ProjectDirPath)
+	e.set("projectRoot", p.ProjectRoot)
+	e.list("targets", p.Targets)
+	return e
+}
+
+func (t *PBXNativeTarget) encode() map[string]interface{} {
+	e := newEncoding(t.ISA(), t.Extra)
+	e.set("buildConfigurationList", t.BuildConfigurationList)
+	e.list("buildPhases", t.BuildPhases)
+	e.list("buildRules", t.BuildRules)
+	e.list("dependencies", t.Dependencies)
+	e.set("name", t.Name)
+	e.optString("productName", t.ProductName)
+	e.optString("productReference", t.ProductReference)
+	e.optString("productType", t.ProductType)
+	return e
+}
+
+func (b *PBXBuildFile) encode() map[string]interface{} {
+	e := newEncoding(b.ISA(), b.Extra)
+	e.optString("fileRef", b.FileRef)
+	e.optDict("settings", b.Settings)
+	return e
+}
+
+func (f *PBXFileReference) encode() map[string]interface{} {
+	e := newEncoding(f.ISA(), f.Extra)
+	e.optString("explicitFileType", f.ExplicitFileType)
+	e.optString("lastKnownFileType", f.LastKnownFileType)
+	e.optString("name", f.Name)
+	e.optString("path", f.Path)
+	e.set("sourceTree", f.SourceTree)
+	return e
+}
+
+func (g *PBXGroup) encode() map[string]interface{} {
+	e := newEncoding(g.ISA(), g.Extra)
+	e.list("children", g.Children)
+	e.optString("name", g.Name)
+	e.optString("path", g.Path)
+	e.set("sourceTree", g.SourceTree)
+	return e
+}
+
+func (p *BuildPhase) encode() map[string]interface{} {
+	e := newEncoding(p.ISA(), p.Extra)
+	e.set("buildActionMask", strconv.Itoa(p.BuildActionMask))
+	if p.Class == "PBXCopyFilesBuildPhase" {
+		e.set("dstPath", p.DstPath)
+		e.set("dstSubfolderSpec", strconv.Itoa(p.DstSubfolderSpec))
+	}
+	e.list("files", p.Files)
+	e.optString("name", p.Name)
+	e.set("runOnlyForDeploymentPostprocessing", strconv.Itoa(p.RunOnlyForDeploymentPostprocessing))
+	return e
+}
+
+func (c *XCBuildConfiguration) encode() map[string]interface{} {
+	e := newEncoding(c.ISA(), c.Extra)
+	e.optString("baseConfigurationReference", c.BaseConfigurationReference)
+	if c.BuildSettings == nil {
+		e["buildSettings"] = map[string]interface{}{}
+	} else {
+		e["buildSettings"] = c.BuildSettings
+	}
+	e.set("name", c.Name)
+	return e
+}
+
+func (l *XCConfigurationList) encode() map[string]interface{} {
+	e := newEncoding(l.ISA(), l.Extra)
+	e.list("buildConfigurations", l.BuildConfigurations)
+	e.set("defaultConfigurationIsVisible", strconv.Itoa(l.DefaultConfigurationIsVisible))
+	e.optString("defaultConfigurationName", l.DefaultConfigurationName)
+	return e
+}
+
+func (g *GenericObject) encode() map[string]interface{} {
+	return newEncoding(g.Class, g.Fields)
+}
+
+// decoder pulls typed fields out of a raw object, remembering the first error.
+type decoder struct {
+	id  string
+	raw map[string]interface{}
+	err error
+}
+
+func (d *decoder) fail(key string, want string, v interface{}) {
+	if d.err == nil {
+		d.err = fmt.Errorf("pbxproj: object %s: %s is %T, want %s", d.id, key, v, want)
+	}
+}
+
+func (d *decoder) string(key string) string {
+	v, ok := d.raw[key]
+	if !ok {
+		return ""
+	}
+	delete(d.raw, key)
+	s, ok := v.(string)
+	if !ok {
+		d.fail(key, "string", v)
+	}
+	return s
+}
+
+func (d *decoder) strings(key string) []string {
+	v, ok := d.raw[key]
+	if !ok {
+		return nil
+	}
+	delete(d.raw, key)
+	items, ok := v.([]interface{})
+	if !ok {
+		d.fail(key, "array", v)
+		return nil
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			d.fail(key, "array of strings", item)
+			return nil
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func (d *decoder) dict(key string) map[string]interface{} {
+	v, ok := d.raw[key]
+	if !ok {
+		return nil
+	}
+	delete(d.raw, key)
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		d.fail(key, "dictionary", v)
+	}
+	return m
+}
+
+func (d *decoder) int(key string) int {
+	s := d.string(key)
+	if s == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil && d.err == nil {
+		d.err = fmt.Errorf("pbxproj: object %s: %s: %w", d.id, key, err)
+	}
+	return n
+}
+
+// rest returns the keys no typed field claimed, so they survive a round trip.
+func (d *decoder) rest() map[string]interface{} {
+	if len(d.raw) == 0 {
+		return nil
+	}
+	return d.raw
+}
+
+type encoding map[string]interface{}
+
+func newEncoding(isa string, extra map[string]interface{}) encoding {
+	e := make(encoding, len(extra)+8)
+	for k, v := range extra {
+		e[k] = v
+	}
+	e["isa"] = isa
+	return e
+}
+
+func (e encoding) set(key, value string) { e[key] = value }
+
+func (e encoding) optString(key, value string) {
+	if value != "" {
+		e[key] = value
+	}
+}
+
+func (e encoding) optDict(key string, value map[string]interface{}) {
+	if len(value) > 0 {
+		e[key] = value
+	}
+}
+
+func (e encoding) list(key string, items []string) {
+	out := make([]interface{}, len(items))
+	for i, s := range items {
+		out[i] = s
+	}
+	e[key] = out
+}
+
+func sortedIDs(objects map[string]Object) []string {
+	ids := make([]string, 0, len(objects))
+	for id := range objects {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,221 @@
+package pbxproj
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Header visibilities stored in a build file's ATTRIBUTES. Project headers
+// carry no attribute.
+const (
+	VisibilityPublic  = "Public"
+	VisibilityPrivate = "Private"
+	VisibilityProject = "Project"
+)
+
+// ParseVisibility maps public, private or project, in any case, to the
+// ATTRIBUTES spelling.
+func ParseVisibility(v string) (string, error) {
+	switch strings.ToLower(v) {
+	case "public":
+		return VisibilityPublic, nil
+	case "private":
+		return VisibilityPrivate, nil
+	case "project":
+		return VisibilityProject, nil
+	}
+	return "", fmt.Errorf("pbxproj: unknown visibility %q (want public, private or project)", v)
+}
+
+// phaseAliases are the short phase names accepted by PhaseClass.
+var phaseAliases = map[string]string{
+	"sources":    "PBXSourcesBuildPhase",
+	"frameworks": "PBXFrameworksBuildPhase",
+	"resources":  "PBXResourcesBuildPhase",
+	"headers":    "PBXHeadersBuildPhase",
+}
+
+// embedFrameworksAlias selects the Embed Frameworks copy phase.
+const embedFrameworksAlias = "embed-frameworks"
+
+// PhaseClass maps a short phase name (sources, frameworks, resources,
+// headers) to its isa.
+func PhaseClass(alias string) (string, bool) {
+	class, ok := phaseAliases[strings.ToLower(alias)]
+	return class, ok
+}
+
+// FindPhase returns the phase of t selected by name: a short alias,
+// "embed-frameworks", or the phase's display name. It returns nil when the
+// target has no such phase.
+func (p *Project) FindPhase(t *PBXNativeTarget, name string) (*BuildPhase, error) {
+	phases, err := p.TargetBuildPhases(t)
+	if err != nil {
+		return nil, err
+	}
+	class, isAlias := PhaseClass(name)
+	for _, phase := range phases {
+		switch {
+		case strings.EqualFold(name, embedFrameworksAlias):
+			if phase.IsEmbedFrameworks() {
+				return phase, nil
+			}
+		case isAlias:
+			if phase.Class == class {
+				return phase, nil
+			}
+		case phase.DisplayName() == name:
+			return phase, nil
+		}
+	}
+	return nil, nil
+}
+
+// EnsurePhase is FindPhase but appends a new phase to t when the name is an
+// alias or "embed-frameworks" and no such phase exists yet.
+func (p *Project) EnsurePhase(t *PBXNativeTarget, name string) (*BuildPhase, error) {
+	phase, err := p.FindPhase(t, name)
+	if err != nil || phase != nil {
+		return phase, err
+	}
+	phase = &BuildPhase{BuildActionMask: 2147483647}
+	if strings.EqualFold(name, embedFrameworksAlias) {
+		phase.Class = "PBXCopyFilesBuildPhase"
+		phase.DstSubfolderSpec = DstSubfolderFrameworks
+		phase.Name = "Embed Frameworks"
+	} else if class, ok := PhaseClass(name); ok {
+		phase.Class = class
+	} else {
+		return nil, fmt.Errorf("pbxproj: target %q has no phase %q", t.Name, name)
+	}
+	p.AddNew(phase, t.ID(), phase.Class, phase.Name)
+	t.BuildPhases = append(t.BuildPhases, phase.ID())
+	return phase, nil
+}
+
+// AddToPhase adds the file reference refID to phase unless it is already
+// there, and returns its build file. settings may be nil.
+func (p *Project) AddToPhase(phase *BuildPhase, refID string, settings map[string]interface{}) (*PBXBuildFile, bool, error) {
+	files, err := p.PhaseBuildFiles(phase)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, bf := range files {
+		if bf.FileRef == refID {
+			return bf, false, nil
+		}
+	}
+	bf := &PBXBuildFile{FileRef: refID, Settings: settings}
+	p.AddNew(bf, phase.ID(), refID)
+	phase.Files = append(phase.Files, bf.ID())
+	return bf, true, nil
+}
+
+// RemoveFromPhase deletes every build file of phase pointing at refID and
+// returns the IDs it removed.
+func (p *Project) RemoveFromPhase(phase *BuildPhase, refID string) []string {
+	var kept, removed []string
+	for _, id := range phase.Files {
+		if bf, ok := p.Objects[id].(*PBXBuildFile); ok && bf.FileRef == refID {
+			p.Remove(id)
+			removed = append(removed, id)
+			continue
+		}
+		kept = append(kept, id)
+	}
+	if kept == nil {
+		kept = []string{}
+	}
+	phase.Files = kept
+	return removed
+}
+
+// FindFileReferences returns references whose resolved path, path or name
+// matches pattern, as MatchPath does.
+func (p *Project) FindFileReferences(pattern string) []*PBXFileReference {
+	var refs []*PBXFileReference
+	resolver := p.PathResolver()
+	for _, id := range p.IDs() {
+		ref, ok := p.Objects[id].(*PBXFileReference)
+		if !ok {
+			continue
+		}
+		for _, candidate := range []string{resolver.ResolvedPath(ref), ref.Path, ref.Name} {
+			if candidate == "" {
+				continue
+			}
+			if MatchPath(pattern, candidate) || candidate == pattern {
+				refs = append(refs, ref)
+				break
+			}
+		}
+	}
+	return refs
+}
+
+// MatchPath reports whether the slash-separated name matches pattern. Each
+// element of pattern uses path.Match wildcards, and a "**" element stands for
+// any number of elements, as in "Libraries/Plugins/iOS/**/*.h".
+func MatchPath(pattern, name string) bool {
+	return matchElements(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchElements(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchElements(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// SetVisibility sets the header visibility of bf, keeping any other
+// ATTRIBUTES. VisibilityProject removes the visibility attribute.
+func (bf *PBXBuildFile) SetVisibility(visibility string) {
+	var attrs []interface{}
+	if bf.Settings != nil {
+		attrs, _ = bf.Settings["ATTRIBUTES"].([]interface{})
+	}
+	var kept []interface{}
+	for _, a := range attrs {
+		if a == VisibilityPublic || a == VisibilityPrivate {
+			continue
+		}
+		kept = append(kept, a)
+	}
+	if visibility == VisibilityPublic || visibility == VisibilityPrivate {
+		kept = append(kept, visibility)
+	}
+	if bf.Settings == nil {
+		bf.Settings = map[string]interface{}{}
+	}
+	if len(kept) == 0 {
+		delete(bf.Settings, "ATTRIBUTES")
+	} else {
+		bf.Settings["ATTRIBUTES"] = kept
+	}
+}
+
+// Visibility returns the header visibility recorded in bf.
+func (bf *PBXBuildFile) Visibility() string {
+	attrs, _ := bf.Settings["ATTRIBUTES"].([]interface{})
+	for _, a := range attrs {
+		if a == VisibilityPublic || a == VisibilityPrivate {
+			return a.(string)
+		}
+	}
+	return VisibilityProject
+}
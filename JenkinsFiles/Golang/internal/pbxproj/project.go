@@ -0,0 +1,341 @@
+package pbxproj
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"howett.net/plist"
+
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/atomicfile"
+)
+
+// ErrNotFound is returned (wrapped) by lookups that match no object.
+var ErrNotFound = errors.New("not found")
+
+// Project is a decoded project.pbxproj.
+type Project struct {
+	// Name is the .xcodeproj name without extension.
+	Name       string
+	RootObject string
+	Objects    map[string]Object
+
+	// header keeps archiveVersion, classes, objectVersion and anything else
+	// stored next to the objects dictionary.
+	header map[string]interface{}
+}
+
+// Load reads and decodes the project.pbxproj at path.
+func Load(path string) (*Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p, err := Parse(data, ProjectName(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Parse decodes project.pbxproj contents in any plist format.
+func Parse(data []byte, name string) (*Project, error) {
+	var raw map[string]interface{}
+	if _, err := plist.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("pbxproj: %w", err)
+	}
+	rawObjects, ok := raw["objects"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("pbxproj: missing objects dictionary")
+	}
+	root, ok := raw["rootObject"].(string)
+	if !ok {
+		return nil, fmt.Errorf("pbxproj: missing rootObject")
+	}
+
+	p := &Project{
+		Name:       name,
+		RootObject: root,
+		Objects:    make(map[string]Object, len(rawObjects)),
+		header:     map[string]interface{}{},
+	}
+	for k, v := range raw {
+		if k != "objects" && k != "rootObject" {
+			p.header[k] = v
+		}
+	}
+	for id, v := range rawObjects {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("pbxproj: object %s is %T, want dictionary", id, v)
+		}
+		obj, err := decodeObject(id, m)
+		if err != nil {
+			return nil, err
+		}
+		p.Objects[id] = obj
+	}
+	return p, nil
+}
+
+// Marshal encodes the project the way Xcode writes it.
+func (p *Project) Marshal() ([]byte, error) {
+	return Marshal(p.Raw(), p.Name)
+}
+
+// Save writes the project to path in Xcode's format, replacing the file
+// atomically.
+func (p *Project) Save(path string) error {
+	data, err := p.Marshal()
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, data, 0644)
+}
+
+// Raw returns the project as an untyped plist tree.
+func (p *Project) Raw() map[string]interface{} {
+	objects := make(map[string]interface{}, len(p.Objects))
+	for id, obj := range p.Objects {
+		objects[id] = obj.encode()
+	}
+	raw := make(map[string]interface{}, len(p.header)+2)
+	for k, v := range p.header {
+		raw[k] = v
+	}
+	raw["objects"] = objects
+	raw["rootObject"] = p.RootObject
+	return raw
+}
+
+// Add stores obj under id, replacing any existing object with that ID.
+func (p *Project) Add(id string, obj Object) {
+	obj.setID(id)
+	p.Objects[id] = obj
+}
+
+// Remove deletes the object with the given ID. References to it are not touched.
+func (p *Project) Remove(id string) {
+	delete(p.Objects, id)
+}
+
+// IDs returns every object ID in sorted order.
+func (p *Project) IDs() []string {
+	return sortedIDs(p.Objects)
+}
+
+// Root returns the PBXProject object.
+func (p *Project) Root() (*PBXProject, error) {
+	obj, err := p.object(p.RootObject, "PBXProject")
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*PBXProject), nil
+}
+
+// NativeTarget returns the PBXNativeTarget with the given ID.
+func (p *Project) NativeTarget(id string) (*PBXNativeTarget, error) {
+	obj, err := p.object(id, "PBXNativeTarget")
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*PBXNativeTarget), nil
+}
+
+// BuildFile returns the PBXBuildFile with the given ID.
+func (p *Project) BuildFile(id string) (*PBXBuildFile, error) {
+	obj, err := p.object(id, "PBXBuildFile")
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*PBXBuildFile), nil
+}
+
+// FileReference returns the PBXFileReference with the given ID.
+func (p *Project) FileReference(id string) (*PBXFileReference, error) {
+	obj, err := p.object(id, "PBXFileReference")
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*PBXFileReference), nil
+}
+
+// Group returns the PBXGroup or PBXVariantGroup with the given ID.
+func (p *Project) Group(id string) (*PBXGroup, error) {
+	obj, ok := p.Objects[id]
+	if !ok {
+		return nil, fmt.Errorf("pbxproj: group %s: %w", id, ErrNotFound)
+	}
+	g, ok := obj.(*PBXGroup)
+	if !ok {
+		return nil, fmt.Errorf("pbxproj: object %s is %s, want PBXGroup", id, obj.ISA())
+	}
+	return g, nil
+}
+
+// BuildPhase returns the build phase with the given ID.
+func (p *Project) BuildPhase(id string) (*BuildPhase, error) {
+	obj, ok := p.Objects[id]
+	if !ok {
+		return nil, fmt.Errorf("pbxproj: build phase %s: %w", id, ErrNotFound)
+	}
+	phase, ok := obj.(*BuildPhase)
+	if !ok {
+		return nil, fmt.Errorf("pbxproj: object %s is %s, want a build phase", id, obj.ISA())
+	}
+	return phase, nil
+}
+
+// BuildConfiguration returns the XCBuildConfiguration with the given ID.
+func (p *Project) BuildConfiguration(id string) (*XCBuildConfiguration, error) {
+	obj, err := p.object(id, "XCBuildConfiguration")
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*XCBuildConfiguration), nil
+}
+
+// ConfigurationList returns the XCConfigurationList with the given ID.
+func (p *Project) ConfigurationList(id string) (*XCConfigurationList, error) {
+	obj, err := p.object(id, "XCConfigurationList")
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*XCConfigurationList), nil
+}
+
+// NativeTargets returns the project's native targets in project order.
+func (p *Project) NativeTargets() ([]*PBXNativeTarget, error) {
+	root, err := p.Root()
+	if err != nil {
+		return nil, err
+	}
+	var targets []*PBXNativeTarget
+	for _, id := range root.Targets {
+		if t, ok := p.Objects[id].(*PBXNativeTarget); ok {
+			targets = append(targets, t)
+		}
+	}
+	return targets, nil
+}
+
+// TargetByName returns the native target with the given name.
+func (p *Project) TargetByName(name string) (*PBXNativeTarget, error) {
+	targets, err := p.NativeTargets()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range targets {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("pbxproj: target %q: %w", name, ErrNotFound)
+}
+
+// FileReferencesByPath returns every file reference whose path is path,
+// ordered by ID.
+func (p *Project) FileReferencesByPath(path string) []*PBXFileReference {
+	var refs []*PBXFileReference
+	for _, id := range sortedIDs(p.Objects) {
+		if ref, ok := p.Objects[id].(*PBXFileReference); ok && ref.Path == path {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// FileReferenceByPath returns the file reference whose path is path and fails
+// if there is none or more than one.
+func (p *Project) FileReferenceByPath(path string) (*PBXFileReference, error) {
+	refs := p.FileReferencesByPath(path)
+	switch len(refs) {
+	case 0:
+		return nil, fmt.Errorf("pbxproj: file reference %q: %w", path, ErrNotFound)
+	case 1:
+		return refs[0], nil
+	default:
+		return nil, fmt.Errorf("pbxproj: %d file references with path %q", len(refs), path)
+	}
+}
+
+// TargetBuildPhases returns the build phases of t in build order.
+func (p *Project) TargetBuildPhases(t *PBXNativeTarget) ([]*BuildPhase, error) {
+	phases := make([]*BuildPhase, 0, len(t.BuildPhases))
+	for _, id := range t.BuildPhases {
+		phase, err := p.BuildPhase(id)
+		if err != nil {
+			return nil, fmt.Errorf("target %q: %w", t.Name, err)
+		}
+		phases = append(phases, phase)
+	}
+	return phases, nil
+}
+
+// TargetBuildPhasesOf returns the build phases of t with the given isa.
+func (p *Project) TargetBuildPhasesOf(t *PBXNativeTarget, class string) ([]*BuildPhase, error) {
+	phases, err := p.TargetBuildPhases(t)
+	if err != nil {
+		return nil, err
+	}
+	var out []*BuildPhase
+	for _, phase := range phases {
+		if phase.Class == class {
+			out = append(out, phase)
+		}
+	}
+	return out, nil
+}
+
+// PhaseBuildFiles returns the build files of phase in order.
+func (p *Project) PhaseBuildFiles(phase *BuildPhase) ([]*PBXBuildFile, error) {
+	files := make([]*PBXBuildFile, 0, len(phase.Files))
+	for _, id := range phase.Files {
+		bf, err := p.BuildFile(id)
+		if err != nil {
+			return nil, fmt.Errorf("phase %s: %w", phase.DisplayName(), err)
+		}
+		files = append(files, bf)
+	}
+	return files, nil
+}
+
+// BuildFilesForRef returns every build file pointing at the file reference
+// refID, ordered by ID.
+func (p *Project) BuildFilesForRef(refID string) []*PBXBuildFile {
+	var files []*PBXBuildFile
+	for _, id := range sortedIDs(p.Objects) {
+		if bf, ok := p.Objects[id].(*PBXBuildFile); ok && bf.FileRef == refID {
+			files = append(files, bf)
+		}
+	}
+	return files
+}
+
+// TargetConfigurations returns the build configurations of t.
+func (p *Project) TargetConfigurations(t *PBXNativeTarget) ([]*XCBuildConfiguration, error) {
+	list, err := p.ConfigurationList(t.BuildConfigurationList)
+	if err != nil {
+		return nil, fmt.Errorf("target %q: %w", t.Name, err)
+	}
+	configs := make([]*XCBuildConfiguration, 0, len(list.BuildConfigurations))
+	for _, id := range list.BuildConfigurations {
+		c, err := p.BuildConfiguration(id)
+		if err != nil {
+			return nil, fmt.Errorf("target %q: %w", t.Name, err)
+		}
+		configs = append(configs, c)
+	}
+	return configs, nil
+}
+
+func (p *Project) object(id, isa string) (Object, error) {
+	obj, ok := p.Objects[id]
+	if !ok {
+		return nil, fmt.Errorf("pbxproj: %s %s: %w", isa, id, ErrNotFound)
+	}
+	if obj.ISA() != isa {
+		return nil, fmt.Errorf("pbxproj: object %s is %s, want %s", id, obj.ISA(), isa)
+	}
+	return obj, nil
+}
@@ -0,0 +1,291 @@
+package pbxproj
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Severity ranks validation issues.
+type Severity int
+
+const (
+	Warning Severity = iota
+	Error
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// Issue kinds reported by Validate.
+const (
+	IssueMissingRoot        = "missing-root"
+	IssueDanglingReference  = "dangling-reference"
+	IssueWrongReferenceType = "wrong-reference-type"
+	IssueDuplicateEntry     = "duplicate-entry"
+	IssueDuplicateBuildFile = "duplicate-build-file"
+	IssueOrphan             = "orphan"
+	IssueUnknownISA         = "unknown-isa"
+)
+
+// Issue is one problem found in the object graph.
+type Issue struct {
+	Severity Severity
+	Kind     string
+	ObjectID string
+	ISA      string
+	Message  string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s [%s] %s (%s): %s", i.Severity, i.Kind, i.ObjectID, i.ISA, i.Message)
+}
+
+var (
+	fileLikeISAs = []string{"PBXFileReference", "PBXVariantGroup", "PBXReferenceProxy", "XCVersionGroup", "PBXGroup", "PBXFileSystemSynchronizedRootGroup"}
+	targetISAs   = []string{"PBXNativeTarget", "PBXAggregateTarget", "PBXLegacyTarget"}
+	packageISAs  = []string{"XCRemoteSwiftPackageReference", "XCLocalSwiftPackageReference"}
+	phaseISAs    = []string{
+		"PBXSourcesBuildPhase", "PBXFrameworksBuildPhase", "PBXResourcesBuildPhase", "PBXHeadersBuildPhase",
+		"PBXCopyFilesBuildPhase", "PBXShellScriptBuildPhase", "PBXRezBuildPhase", "PBXAppleScriptBuildPhase",
+	}
+)
+
+// referenceKeys maps every key that holds object IDs to the isas it may point at.
+var referenceKeys = map[string][]string{
+	"baseConfigurationReference": {"PBXFileReference"},
+	"buildConfigurationList":     {"XCConfigurationList"},
+	"buildConfigurations":        {"XCBuildConfiguration"},
+	"buildPhases":                phaseISAs,
+	"buildRules":                 {"PBXBuildRule"},
+	"children":                   fileLikeISAs,
+	"containerPortal":            {"PBXProject", "PBXFileReference"},
+	"dependencies":               {"PBXTargetDependency"},
+	"fileRef":                    fileLikeISAs,
+	"files":                      {"PBXBuildFile"},
+	"mainGroup":                  {"PBXGroup"},
+	"package":                    packageISAs,
+	"packageProductDependencies": {"XCSwiftPackageProductDependency"},
+	"packageReferences":          packageISAs,
+	"productRef":                 {"XCSwiftPackageProductDependency"},
+	"productRefGroup":            {"PBXGroup"},
+	"productReference":           {"PBXFileReference", "PBXReferenceProxy"},
+	"remoteRef":                  {"PBXContainerItemProxy"},
+	"target":                     targetISAs,
+	"targetProxy":                {"PBXContainerItemProxy"},
+	"targets":                    targetISAs,
+	"ProductGroup":               {"PBXGroup"},
+	"ProjectRef":                 {"PBXFileReference"},
+}
+
+// knownISAs lists every isa Xcode is expected to understand.
+var knownISAs = map[string]bool{
+	"PBXAggregateTarget":                             true,
+	"PBXBuildFile":                                   true,
+	"PBXBuildRule":                                   true,
+	"PBXContainerItemProxy":                          true,
+	"PBXFileReference":                               true,
+	"PBXFileSystemSynchronizedRootGroup":             true,
+	"PBXFileSystemSynchronizedBuildFileExceptionSet": true,
+	"PBXFileSystemSynchronizedGroupBuildPhaseMembershipExceptionSet": true,
+	"PBXGroup":                        true,
+	"PBXLegacyTarget":                 true,
+	"PBXNativeTarget":                 true,
+	"PBXProject":                      true,
+	"PBXReferenceProxy":               true,
+	"PBXTargetDependency":             true,
+	"PBXVariantGroup":                 true,
+	"XCBuildConfiguration":            true,
+	"XCConfigurationList":             true,
+	"XCLocalSwiftPackageReference":    true,
+	"XCRemoteSwiftPackageReference":   true,
+	"XCSwiftPackageProductDependency": true,
+	"XCVersionGroup":                  true,
+}
+
+// orphanParents names, per isa, the keys that must reference an object for it
+// to be reachable.
+var orphanParents = map[string][]string{
+	"PBXBuildFile":          {"files"},
+	"PBXFileReference":      {"children", "productReference", "containerPortal", "ProjectRef"},
+	"PBXVariantGroup":       {"children"},
+	"PBXReferenceProxy":     {"children", "productReference"},
+	"PBXGroup":              {"children", "mainGroup", "productRefGroup", "ProductGroup"},
+	"XCBuildConfiguration":  {"buildConfigurations"},
+	"XCConfigurationList":   {"buildConfigurationList"},
+	"PBXTargetDependency":   {"dependencies"},
+	"PBXContainerItemProxy": {"targetProxy", "remoteRef"},
+	"PBXNativeTarget":       {"targets"},
+	"PBXAggregateTarget":    {"targets"},
+	"PBXLegacyTarget":       {"targets"},
+}
+
+// reference is one edge of the object graph.
+type reference struct {
+	from string
+	key  string
+	to   string
+}
+
+// Validate walks the full object graph and reports broken references,
+// duplicate entries, orphaned objects and unknown isa types.
+func (p *Project) Validate() []Issue {
+	var issues []Issue
+	report := func(sev Severity, kind, id, message string) {
+		isa := ""
+		if obj, ok := p.Objects[id]; ok {
+			isa = obj.ISA()
+		}
+		issues = append(issues, Issue{Severity: sev, Kind: kind, ObjectID: id, ISA: isa, Message: message})
+	}
+
+	if _, ok := p.Objects[p.RootObject]; !ok {
+		report(Error, IssueMissingRoot, p.RootObject, "rootObject does not exist")
+	} else if p.Objects[p.RootObject].ISA() != "PBXProject" {
+		report(Error, IssueWrongReferenceType, p.RootObject, "rootObject is not a PBXProject")
+	}
+
+	referrers := map[string]map[string]bool{}
+	for _, id := range p.IDs() {
+		obj := p.Objects[id]
+		if phase, ok := obj.(*BuildPhase); ok {
+			p.checkDuplicateBuildFiles(phase, report)
+		}
+		if !knownISAs[obj.ISA()] && !isBuildPhase(obj.ISA()) {
+			report(Warning, IssueUnknownISA, id, fmt.Sprintf("unknown isa %q", obj.ISA()))
+		}
+
+		raw := obj.encode()
+		for _, ref := range collectReferences(id, raw) {
+			if referrers[ref.to] == nil {
+				referrers[ref.to] = map[string]bool{}
+			}
+			referrers[ref.to][ref.key] = true
+
+			target, ok := p.Objects[ref.to]
+			if !ok {
+				report(Error, IssueDanglingReference, id, fmt.Sprintf("%s points at missing object %s", ref.key, ref.to))
+				continue
+			}
+			if !contains(referenceKeys[ref.key], target.ISA()) {
+				report(Error, IssueWrongReferenceType, id, fmt.Sprintf("%s points at %s %s", ref.key, target.ISA(), ref.to))
+			}
+		}
+		for key, ids := range listReferences(raw) {
+			seen := map[string]bool{}
+			for _, ref := range ids {
+				if seen[ref] {
+					report(Error, IssueDuplicateEntry, id, fmt.Sprintf("%s lists %s more than once", key, ref))
+				}
+				seen[ref] = true
+			}
+		}
+	}
+
+	for _, id := range p.IDs() {
+		obj := p.Objects[id]
+		parents, ok := orphanParents[obj.ISA()]
+		if !ok && isBuildPhase(obj.ISA()) {
+			parents = []string{"buildPhases"}
+		}
+		if len(parents) == 0 || id == p.RootObject {
+			continue
+		}
+		reachable := false
+		for _, key := range parents {
+			if referrers[id][key] {
+				reachable = true
+				break
+			}
+		}
+		if !reachable {
+			report(Warning, IssueOrphan, id, "not referenced by any "+strings.Join(parents, ", "))
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Severity != issues[j].Severity {
+			return issues[i].Severity > issues[j].Severity
+		}
+		if issues[i].ObjectID != issues[j].ObjectID {
+			return issues[i].ObjectID < issues[j].ObjectID
+		}
+		if issues[i].Kind != issues[j].Kind {
+			return issues[i].Kind < issues[j].Kind
+		}
+		return issues[i].Message < issues[j].Message
+	})
+	return issues
+}
+
+// checkDuplicateBuildFiles reports phases containing the same file twice.
+func (p *Project) checkDuplicateBuildFiles(phase *BuildPhase, report func(Severity, string, string, string)) {
+	seen := map[string]string{}
+	for _, id := range phase.Files {
+		bf, ok := p.Objects[id].(*PBXBuildFile)
+		if !ok || bf.FileRef == "" {
+			continue
+		}
+		if first, dup := seen[bf.FileRef]; dup && first != id {
+			report(Error, IssueDuplicateBuildFile, phase.ID(), fmt.Sprintf("file %s is added by both %s and %s", bf.FileRef, first, id))
+			continue
+		}
+		seen[bf.FileRef] = id
+	}
+}
+
+// collectReferences returns every ID held by a reference key of raw,
+// including inside nested dictionaries such as projectReferences.
+func collectReferences(from string, raw map[string]interface{}) []reference {
+	var refs []reference
+	for _, key := range sortedKeys(raw) {
+		switch v := raw[key].(type) {
+		case string:
+			if _, ok := referenceKeys[key]; ok {
+				refs = append(refs, reference{from: from, key: key, to: v})
+			}
+		case []interface{}:
+			for _, item := range v {
+				switch iv := item.(type) {
+				case string:
+					if _, ok := referenceKeys[key]; ok {
+						refs = append(refs, reference{from: from, key: key, to: iv})
+					}
+				case map[string]interface{}:
+					refs = append(refs, collectReferences(from, iv)...)
+				}
+			}
+		}
+	}
+	return refs
+}
+
+// listReferences returns the ID lists of raw keyed by their key.
+func listReferences(raw map[string]interface{}) map[string][]string {
+	lists := map[string][]string{}
+	for key, v := range raw {
+		items, ok := v.([]interface{})
+		if _, isRef := referenceKeys[key]; !ok || !isRef {
+			continue
+		}
+		for _, item := range items {
+			if s, ok := item.(string); ok {
+				lists[key] = append(lists[key], s)
+			}
+		}
+	}
+	return lists
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package pbxproj
+
+import "testing"
+
+// id is an object ID of the golden project.
+func id(n string) string { return "E5C1A0012B0D4E5F00000" + n }
+
+func TestValidateGolden(t *testing.T) {
+	p, err := Load(goldenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, issue := range p.Validate() {
+		t.Errorf("golden project: %s", issue)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		replacements []string
+		severity     Severity
+		kind         string
+		object       string
+	}{
+		{"dangling reference", []string{
+			id("101") + " /* AppDelegate.swift in Sources */,\n", id("199") + ",\n",
+		}, Error, IssueDanglingReference, id("508")},
+		{"wrong reference type", []string{
+			"fileRef = " + id("201"), "fileRef = " + id("801"),
+		}, Error, IssueWrongReferenceType, id("101")},
+		{"duplicate entry", []string{
+			"\t\t\t\t" + id("201") + " /* AppDelegate.swift */,\n",
+			"\t\t\t\t" + id("201") + " /* AppDelegate.swift */,\n\t\t\t\t" + id("201") + ",\n",
+		}, Error, IssueDuplicateEntry, id("601")},
+		{"duplicate build file", []string{
+			"/* End PBXBuildFile section */",
+			id("109") + " = {isa = PBXBuildFile; fileRef = " + id("202") + "; };\n/* End PBXBuildFile section */",
+			"\t\t\t\t" + id("102") + " /* ContentView.swift in Sources */,\n",
+			"\t\t\t\t" + id("102") + " /* ContentView.swift in Sources */,\n\t\t\t\t" + id("109") + ",\n",
+		}, Error, IssueDuplicateBuildFile, id("508")},
+		{"orphan", []string{
+			"\t\t\t\t" + id("208") + " /* Info.plist */,\n", "",
+		}, Warning, IssueOrphan, id("208")},
+		{"unknown isa", []string{
+			"/* End PBXBuildFile section */",
+			id("199") + " = {isa = PBXMadeUpThing; };\n/* End PBXBuildFile section */",
+		}, Warning, IssueUnknownISA, id("199")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse(editGolden(t, tt.replacements...), "Golden")
+			if err != nil {
+				t.Fatal(err)
+			}
+			issues := p.Validate()
+			found := false
+			for _, issue := range issues {
+				if issue.Kind == tt.kind && issue.ObjectID == tt.object {
+					found = true
+					if issue.Severity != tt.severity {
+						t.Errorf("%s is a %s, want a %s", issue, issue.Severity, tt.severity)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("no %s issue on %s in %v", tt.kind, tt.object, issues)
+			}
+			// Errors sort before warnings.
+			for i := 1; i < len(issues); i++ {
+				if issues[i].Severity > issues[i-1].Severity {
+					t.Errorf("issue %d (%s) sorts after a warning", i, issues[i])
+				}
+			}
+		})
+	}
+}
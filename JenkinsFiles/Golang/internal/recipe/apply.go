@@ -0,0 +1,317 @@
+package recipe
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/pbxproj"
+)
+
+// Apply runs every operation of r against project in order and returns a
+// description of each change made. It stops at the first failing operation;
+// project may then be partially modified and should not be saved.
+func Apply(project *pbxproj.Project, r *Recipe) ([]string, error) {
+	var changes []string
+	for i, op := range r.Operations {
+		c, err := apply(project, op)
+		if err != nil {
+			return changes, fmt.Errorf("recipe %q: operation %d (%s): %w", r.Name, i+1, op.Op, err)
+		}
+		changes = append(changes, c...)
+	}
+	return changes, nil
+}
+
+func apply(project *pbxproj.Project, op Operation) ([]string, error) {
+	switch op.Op {
+	case OpAddFile:
+		return addFile(project, op)
+	case OpRemoveFile:
+		return removeFile(project, op)
+	case OpMoveFile:
+		return moveFile(project, op)
+	case OpSetBuildSetting:
+		return setBuildSetting(project, op)
+	case OpAppendBuildSetting, OpRemoveBuildSetting:
+		return editBuildSettingList(project, op)
+	case OpSetHeaderVisibility:
+		return setHeaderVisibility(project, op)
+	case OpEmbedFramework:
+		return embedFramework(project, op)
+	}
+	return nil, fmt.Errorf("unknown op %q", op.Op)
+}
+
+func addFile(project *pbxproj.Project, op Operation) ([]string, error) {
+	target, err := findTarget(project, op.Target)
+	if err != nil {
+		return nil, err
+	}
+	ref, changes, err := fileReference(project, op)
+	if err != nil {
+		return nil, err
+	}
+	phase, err := project.EnsurePhase(target, op.Phase)
+	if err != nil {
+		return nil, err
+	}
+	var settings map[string]interface{}
+	if len(op.Attributes) > 0 {
+		attrs := make([]interface{}, len(op.Attributes))
+		for i, a := range op.Attributes {
+			attrs[i] = a
+		}
+		settings = map[string]interface{}{"ATTRIBUTES": attrs}
+	}
+	_, created, err := project.AddToPhase(phase, ref.ID(), settings)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return changes, nil
+	}
+	return append(changes, fmt.Sprintf("added %s to %s/%s", ref.DisplayName(), target.Name, phase.DisplayName())), nil
+}
+
+func removeFile(project *pbxproj.Project, op Operation) ([]string, error) {
+	target, err := findTarget(project, op.Target)
+	if err != nil {
+		return nil, err
+	}
+	return removeFromTarget(project, target, op.Phase, op.File)
+}
+
+func moveFile(project *pbxproj.Project, op Operation) ([]string, error) {
+	from, err := findTarget(project, op.From)
+	if err != nil {
+		return nil, err
+	}
+	refs := project.FindFileReferences(op.File)
+	var changes []string
+	if op.FileType == pbxproj.FolderFileType {
+		// Only the folder reference addFile will use, not every folder
+		// with the same name.
+		ref, refChanges, err := fileReference(project, op)
+		if err != nil {
+			return nil, err
+		}
+		refs, changes = []*pbxproj.PBXFileReference{ref}, refChanges
+	}
+	removed, err := removeRefsFromTarget(project, from, op.Phase, refs)
+	if err != nil {
+		return nil, err
+	}
+	changes = append(changes, removed...)
+	added, err := addFile(project, Operation{Target: op.To, Phase: op.Phase, File: op.File, Group: op.Group, SourceTree: op.SourceTree, FileType: op.FileType, Explicit: op.Explicit})
+	if err != nil {
+		return nil, err
+	}
+	return append(changes, added...), nil
+}
+
+func setBuildSetting(project *pbxproj.Project, op Operation) ([]string, error) {
+	value, err := settingValue(&op.Value)
+	if err != nil {
+		return nil, err
+	}
+	scope, configs, err := configurations(project, op)
+	if err != nil {
+		return nil, err
+	}
+	var changes []string
+	for _, c := range configs {
+		if fmt.Sprint(c.BuildSettings[op.Key]) == fmt.Sprint(value) {
+			continue
+		}
+		c.Set(op.Key, value)
+		changes = append(changes, fmt.Sprintf("set %s = %v in %s/%s", op.Key, value, scope, c.Name))
+	}
+	return changes, nil
+}
+
+func editBuildSettingList(project *pbxproj.Project, op Operation) ([]string, error) {
+	values, err := settingList(&op.Value)
+	if err != nil {
+		return nil, err
+	}
+	scope, configs, err := configurations(project, op)
+	if err != nil {
+		return nil, err
+	}
+	var changes []string
+	for _, c := range configs {
+		switch {
+		case op.Op == OpAppendBuildSetting:
+			if c.Append(op.Key, values...) {
+				changes = append(changes, fmt.Sprintf("appended %s to %s in %s/%s", strings.Join(values, " "), op.Key, scope, c.Name))
+			}
+		case len(values) == 0:
+			if c.Delete(op.Key) {
+				changes = append(changes, fmt.Sprintf("removed %s from %s/%s", op.Key, scope, c.Name))
+			}
+		default:
+			if c.RemoveValues(op.Key, values...) {
+				changes = append(changes, fmt.Sprintf("removed %s from %s in %s/%s", strings.Join(values, " "), op.Key, scope, c.Name))
+			}
+		}
+	}
+	return changes, nil
+}
+
+// configurations returns the configurations op selects, with a scope name
+// for messages: the target name, or "project" when op has no target.
+func configurations(project *pbxproj.Project, op Operation) (string, []*pbxproj.XCBuildConfiguration, error) {
+	if op.Target == "" {
+		configs, err := project.Configurations(nil, op.Configuration)
+		return "project", configs, err
+	}
+	target, err := findTarget(project, op.Target)
+	if err != nil {
+		return "", nil, err
+	}
+	configs, err := project.Configurations(target, op.Configuration)
+	return target.Name, configs, err
+}
+
+func setHeaderVisibility(project *pbxproj.Project, op Operation) ([]string, error) {
+	vis, err := pbxproj.ParseVisibility(op.Visibility)
+	if err != nil {
+		return nil, err
+	}
+	target, err := findTarget(project, op.Target)
+	if err != nil {
+		return nil, err
+	}
+	refs := project.FindFileReferences(op.File)
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("no file matches %q", op.File)
+	}
+	phase, err := project.EnsurePhase(target, "headers")
+	if err != nil {
+		return nil, err
+	}
+	// Adding build files does not move references between groups, so one
+	// resolver serves every header.
+	resolver := project.PathResolver()
+	var changes []string
+	for _, ref := range refs {
+		bf, created, err := project.AddToPhase(phase, ref.ID(), nil)
+		if err != nil {
+			return nil, err
+		}
+		if !created && bf.Visibility() == vis {
+			continue
+		}
+		bf.SetVisibility(vis)
+		changes = append(changes, fmt.Sprintf("made %s %s in %s", resolver.ResolvedPath(ref), strings.ToLower(vis), target.Name))
+	}
+	return changes, nil
+}
+
+func embedFramework(project *pbxproj.Project, op Operation) ([]string, error) {
+	if op.SourceTree == "" {
+		op.SourceTree = pbxproj.SourceTreeSourceRoot
+	}
+	if op.FileType == "" {
+		op.FileType = pbxproj.FileTypeForPath(op.File)
+		op.Explicit = true
+	}
+	if op.Group == "" {
+		op.Group = "Frameworks"
+	}
+	op.Phase = "embed-frameworks"
+	op.Attributes = []string{"CodeSignOnCopy", "RemoveHeadersOnCopy"}
+	return addFile(project, op)
+}
+
+// fileReference finds the reference op.File names, creating it in op.Group
+// when nothing matches, and describes what it changed. A folder reference
+// (fileType folder) is op.File relative to SOURCE_ROOT in op.Group, as
+// pbxproj.EnsureFolderReference finds or makes it.
+func fileReference(project *pbxproj.Project, op Operation) (*pbxproj.PBXFileReference, []string, error) {
+	if op.FileType == pbxproj.FolderFileType {
+		return project.EnsureFolderReference(op.Group, op.File)
+	}
+	refs := project.FindFileReferences(op.File)
+	switch len(refs) {
+	case 1:
+		return refs[0], nil, nil
+	case 0:
+		ref, err := project.AddFileReference(op.Group, pbxproj.FileSpec{
+			Path:       op.File,
+			SourceTree: op.SourceTree,
+			FileType:   op.FileType,
+			Explicit:   op.Explicit,
+		})
+		return ref, nil, err
+	default:
+		return nil, nil, fmt.Errorf("%d file references match %q", len(refs), op.File)
+	}
+}
+
+func removeFromTarget(project *pbxproj.Project, target *pbxproj.PBXNativeTarget, phaseName, file string) ([]string, error) {
+	return removeRefsFromTarget(project, target, phaseName, project.FindFileReferences(file))
+}
+
+// removeRefsFromTarget removes refs from the phases of target phaseName
+// selects: every phase of the class an alias such as "resources" names,
+// the phase with that display name, or all phases when it is empty.
+func removeRefsFromTarget(project *pbxproj.Project, target *pbxproj.PBXNativeTarget, phaseName string, refs []*pbxproj.PBXFileReference) ([]string, error) {
+	phases, err := project.TargetBuildPhases(target)
+	if err != nil {
+		return nil, err
+	}
+	if class, ok := pbxproj.PhaseClass(phaseName); ok {
+		if phases, err = project.TargetBuildPhasesOf(target, class); err != nil {
+			return nil, err
+		}
+	} else if phaseName != "" {
+		phase, err := project.FindPhase(target, phaseName)
+		if err != nil {
+			return nil, err
+		}
+		phases = nil
+		if phase != nil {
+			phases = []*pbxproj.BuildPhase{phase}
+		}
+	}
+	var changes []string
+	for _, ref := range refs {
+		for _, phase := range phases {
+			if removed := project.RemoveFromPhase(phase, ref.ID()); len(removed) > 0 {
+				changes = append(changes, fmt.Sprintf("removed %s from %s/%s", ref.DisplayName(), target.Name, phase.DisplayName()))
+			}
+		}
+	}
+	return changes, nil
+}
+
+// findTarget returns the native target named name; name may also be a
+// path.Match pattern such as "*-mobile" as long as it selects one target.
+func findTarget(project *pbxproj.Project, name string) (*pbxproj.PBXNativeTarget, error) {
+	if t, err := project.TargetByName(name); err == nil {
+		return t, nil
+	}
+	targets, err := project.NativeTargets()
+	if err != nil {
+		return nil, err
+	}
+	var matches []*pbxproj.PBXNativeTarget
+	for _, t := range targets {
+		if ok, _ := path.Match(name, t.Name); ok {
+			matches = append(matches, t)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("target %q: %w", name, pbxproj.ErrNotFound)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, t := range matches {
+		names[i] = t.Name
+	}
+	return nil, fmt.Errorf("target pattern %q matches %s", name, strings.Join(names, ", "))
+}
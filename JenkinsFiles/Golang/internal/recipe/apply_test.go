@@ -0,0 +1,79 @@
+package recipe
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/pbxproj"
+)
+
+// unityProject holds Unity's Data folder reference, still typed
+// explicitly, and a plugin folder also called Data.
+const unityProject = `// !$*UTF8*$!
+{
+	archiveVersion = 1;
+	classes = {
+	};
+	objectVersion = 56;
+	objects = {
+		B1 = {isa = PBXBuildFile; fileRef = F1; };
+		B2 = {isa = PBXBuildFile; fileRef = F2; };
+		F1 = {isa = PBXFileReference; explicitFileType = folder; path = Data; sourceTree = SOURCE_ROOT; };
+		F2 = {isa = PBXFileReference; lastKnownFileType = folder; path = Data; sourceTree = "<group>"; };
+		H1 = {isa = PBXFileReference; lastKnownFileType = sourcecode.c.h; path = A.h; sourceTree = "<group>"; };
+		G0 = {isa = PBXGroup; children = (F1, G1, ); sourceTree = "<group>"; };
+		G1 = {isa = PBXGroup; children = (H1, F2, ); path = Libraries/Plugins/iOS; sourceTree = "<group>"; };
+		R1 = {isa = PBXResourcesBuildPhase; buildActionMask = 2147483647; files = (B1, B2, ); runOnlyForDeploymentPostprocessing = 0; };
+		R2 = {isa = PBXResourcesBuildPhase; buildActionMask = 2147483647; files = ( ); runOnlyForDeploymentPostprocessing = 0; };
+		T1 = {isa = PBXNativeTarget; buildConfigurationList = L1; buildPhases = (R1, ); name = "Unity-iPhone"; productName = "Unity-iPhone"; productType = "com.apple.product-type.application"; };
+		T2 = {isa = PBXNativeTarget; buildConfigurationList = L2; buildPhases = (R2, ); name = UnityFramework; productName = UnityFramework; productType = "com.apple.product-type.framework"; };
+		C1 = {isa = XCBuildConfiguration; buildSettings = {}; name = Release; };
+		C2 = {isa = XCBuildConfiguration; buildSettings = {}; name = Release; };
+		C3 = {isa = XCBuildConfiguration; buildSettings = {}; name = Release; };
+		L1 = {isa = XCConfigurationList; buildConfigurations = (C1, ); };
+		L2 = {isa = XCConfigurationList; buildConfigurations = (C2, ); };
+		L3 = {isa = XCConfigurationList; buildConfigurations = (C3, ); };
+		PR = {isa = PBXProject; buildConfigurationList = L3; mainGroup = G0; targets = (T1, T2, ); };
+	};
+	rootObject = PR;
+}
+`
+
+func TestUnityFrameworkRecipe(t *testing.T) {
+	project, err := pbxproj.Parse([]byte(unityProject), "Unity-iPhone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := Load("../../recipes/unity-framework.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := Apply(project, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(strings.Join(changes, "\n"))
+
+	r1, _ := project.BuildPhase("R1")
+	if len(r1.Files) != 1 || r1.Files[0] != "B2" {
+		t.Errorf("Unity-iPhone resources = %v, want only the plugin's Data", r1.Files)
+	}
+	r2, _ := project.BuildPhase("R2")
+	files, _ := project.PhaseBuildFiles(r2)
+	if len(files) != 1 || files[0].FileRef != "F1" {
+		t.Errorf("UnityFramework resources = %+v, want Unity's Data", files)
+	}
+	f1, _ := project.FileReference("F1")
+	if f1.ExplicitFileType != "" || f1.LastKnownFileType != pbxproj.FolderFileType {
+		t.Errorf("Data = %+v, want a folder reference", f1)
+	}
+
+	// A second run changes nothing.
+	again, err := Apply(project, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != 0 {
+		t.Errorf("second run changed %v", again)
+	}
+}
@@ -0,0 +1,230 @@
+// Package recipe applies declarative patch recipes to Xcode projects.
+//
+// A recipe is a YAML (or JSON) document listing operations:
+//
+//	name: unity-framework
+//	operations:
+//	  - op: move-file
+//	    file: Data
+//	    from: Unity-iPhone
+//	    to: UnityFramework
+//	    phase: resources
+//	  - op: set-build-setting
+//	    target: UnityFramework
+//	    configuration: all
+//	    key: ENABLE_BITCODE
+//	    value: "NO"
+package recipe
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/pbxproj"
+)
+
+// Operation names.
+const (
+	OpAddFile             = "add-file"
+	OpRemoveFile          = "remove-file"
+	OpMoveFile            = "move-file"
+	OpSetBuildSetting     = "set-build-setting"
+	OpAppendBuildSetting  = "append-build-setting"
+	OpRemoveBuildSetting  = "remove-build-setting"
+	OpSetHeaderVisibility = "set-header-visibility"
+	OpEmbedFramework      = "embed-framework"
+)
+
+// Recipe is a named list of operations.
+type Recipe struct {
+	Name        string      `yaml:"name"`
+	Description string      `yaml:"description"`
+	Operations  []Operation `yaml:"operations"`
+}
+
+// Operation is one step of a recipe. Which fields apply depends on Op:
+//
+//	add-file               target, phase, file, [group, sourceTree, fileType, explicit, attributes]
+//	remove-file            target, file, [phase]
+//	move-file              from, to, phase, file, [group, sourceTree, fileType, explicit]
+//	set-build-setting      key, value, [target, configuration]
+//	append-build-setting   key, value (string or list), [target, configuration]
+//	remove-build-setting   key, [value, target, configuration]
+//	set-header-visibility  target, file (glob, ** for any folders), visibility
+//	embed-framework        target, file, [group, sourceTree]
+//
+// With fileType folder, file is a folder relative to SOURCE_ROOT and only
+// its folder reference in group (the main group by default) is used, as
+// pbxproj.EnsureFolderReference finds or makes it; sourceTree and explicit
+// do not apply.
+type Operation struct {
+	Op            string   `yaml:"op"`
+	Target        string   `yaml:"target"`
+	From          string   `yaml:"from"`
+	To            string   `yaml:"to"`
+	Phase         string   `yaml:"phase"`
+	File          string   `yaml:"file"`
+	Group         string   `yaml:"group"`
+	SourceTree    string   `yaml:"sourceTree"`
+	FileType      string   `yaml:"fileType"`
+	Explicit      bool     `yaml:"explicit"`
+	Attributes    []string `yaml:"attributes"`
+	Configuration string   `yaml:"configuration"`
+	Key           string   `yaml:"key"`
+	Visibility    string   `yaml:"visibility"`
+	// Value is kept as written, so an unquoted 12.0 stays "12.0".
+	Value yaml.Node `yaml:"value"`
+}
+
+// Load reads and validates the recipe at path.
+func Load(path string) (*Recipe, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Parse decodes and validates a recipe. JSON is accepted as YAML.
+func Parse(data []byte) (*Recipe, error) {
+	var r Recipe
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	// A misspelled key would otherwise be ignored along with what it sets.
+	dec.KnownFields(true)
+	if err := dec.Decode(&r); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("recipe: %w", err)
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Validate checks that every operation is known and has its required fields.
+func (r *Recipe) Validate() error {
+	if len(r.Operations) == 0 {
+		return fmt.Errorf("recipe %q: no operations", r.Name)
+	}
+	for i, op := range r.Operations {
+		if err := op.validate(); err != nil {
+			return fmt.Errorf("recipe %q: operation %d (%s): %w", r.Name, i+1, op.Op, err)
+		}
+	}
+	return nil
+}
+
+func (op Operation) validate() error {
+	var required map[string]string
+	switch op.Op {
+	case OpAddFile:
+		required = map[string]string{"target": op.Target, "phase": op.Phase, "file": op.File}
+	case OpRemoveFile:
+		required = map[string]string{"target": op.Target, "file": op.File}
+	case OpMoveFile:
+		required = map[string]string{"from": op.From, "to": op.To, "phase": op.Phase, "file": op.File}
+	case OpSetBuildSetting:
+		required = map[string]string{"key": op.Key}
+		if _, err := settingValue(&op.Value); err != nil {
+			return err
+		}
+	case OpAppendBuildSetting, OpRemoveBuildSetting:
+		required = map[string]string{"key": op.Key}
+		values, err := settingList(&op.Value)
+		if err != nil {
+			return err
+		}
+		if op.Op == OpAppendBuildSetting && values == nil {
+			return fmt.Errorf("missing value")
+		}
+	case OpSetHeaderVisibility:
+		required = map[string]string{"target": op.Target, "file": op.File, "visibility": op.Visibility}
+		if _, err := pbxproj.ParseVisibility(op.Visibility); err != nil {
+			return err
+		}
+	case OpEmbedFramework:
+		required = map[string]string{"target": op.Target, "file": op.File}
+	case "":
+		return fmt.Errorf("missing op")
+	default:
+		return fmt.Errorf("unknown op %q", op.Op)
+	}
+	for _, field := range []string{"target", "from", "to", "phase", "file", "key", "visibility"} {
+		if v, ok := required[field]; ok && strings.TrimSpace(v) == "" {
+			return fmt.Errorf("missing %s", field)
+		}
+	}
+	return nil
+}
+
+// settingValue converts a decoded YAML value into a build-setting value.
+// Numbers keep the text they were written with: build settings such as
+// IPHONEOS_DEPLOYMENT_TARGET are strings, and 13.10 is not 13.1.
+func settingValue(n *yaml.Node) (interface{}, error) {
+	switch n.Kind {
+	case 0:
+		// No value key at all.
+		return nil, fmt.Errorf("missing value")
+	case yaml.AliasNode:
+		return settingValue(n.Alias)
+	case yaml.ScalarNode:
+		switch n.Tag {
+		case "!!null":
+			return nil, fmt.Errorf("missing value")
+		case "!!bool":
+			var b bool
+			if err := n.Decode(&b); err != nil {
+				return nil, err
+			}
+			if b {
+				return "YES", nil
+			}
+			return "NO", nil
+		}
+		return n.Value, nil
+	case yaml.SequenceNode:
+		out := make([]interface{}, len(n.Content))
+		for i, item := range n.Content {
+			if item.Kind == yaml.SequenceNode {
+				return nil, fmt.Errorf("nested lists are not valid build settings")
+			}
+			s, err := settingValue(item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = s
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("line %d: unsupported value, want a string or a list", n.Line)
+	}
+}
+
+// settingList converts a decoded YAML value into list entries for the
+// append and remove operations.
+func settingList(n *yaml.Node) ([]string, error) {
+	if n.Kind == 0 || n.Tag == "!!null" {
+		return nil, nil
+	}
+	value, err := settingValue(n)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return []string{value.(string)}, nil
+	}
+	out := make([]string, len(list))
+	for i, item := range list {
+		out[i] = item.(string)
+	}
+	return out, nil
+}
@@ -0,0 +1,91 @@
+package recipe
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSettingValuesKeepTheirText(t *testing.T) {
+	r, err := Parse([]byte(`
+name: values
+operations:
+  - {op: set-build-setting, key: IPHONEOS_DEPLOYMENT_TARGET, value: 12.0}
+  - {op: set-build-setting, key: MARKETING_VERSION, value: 13.10}
+  - {op: set-build-setting, key: CURRENT_PROJECT_VERSION, value: 7}
+  - {op: set-build-setting, key: ENABLE_BITCODE, value: false}
+  - {op: set-build-setting, key: SWIFT_VERSION, value: "5.0"}
+  - {op: set-build-setting, key: OTHER_LDFLAGS, value: [-ObjC, 1.50, true]}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{"12.0", "13.10", "7", "NO", "5.0", []interface{}{"-ObjC", "1.50", "YES"}}
+	for i, op := range r.Operations {
+		got, err := settingValue(&op.Value)
+		if err != nil {
+			t.Errorf("%s: %v", op.Key, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("%s = %#v, want %#v", op.Key, got, want[i])
+		}
+	}
+}
+
+func TestSettingValuesFromJSON(t *testing.T) {
+	r, err := Parse([]byte(`{"name": "json", "operations": [
+		{"op": "set-build-setting", "key": "IPHONEOS_DEPLOYMENT_TARGET", "value": 12.0},
+		{"op": "append-build-setting", "key": "OTHER_LDFLAGS", "value": ["-ObjC", "-lz"]}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := settingValue(&r.Operations[0].Value); got != "12.0" {
+		t.Errorf("value = %#v, want \"12.0\"", got)
+	}
+	if got, _ := settingList(&r.Operations[1].Value); !reflect.DeepEqual(got, []string{"-ObjC", "-lz"}) {
+		t.Errorf("list = %#v", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, c := range []struct{ name, recipe, want string }{
+		{"misspelled key", "name: x\noperations:\n  - {op: set-build-setting, key: A, vaule: 1}\n", "field vaule not found"},
+		{"unknown top-level key", "name: x\noperation: []\n", "field operation not found"},
+		{"null value", "name: x\noperations:\n  - {op: set-build-setting, key: A, value: ~}\n", "missing value"},
+		{"nested list", "name: x\noperations:\n  - {op: set-build-setting, key: A, value: [[a]]}\n", "nested lists"},
+		{"map value", "name: x\noperations:\n  - {op: set-build-setting, key: A, value: {a: b}}\n", "unsupported value"},
+		{"empty", "", "no operations"},
+	} {
+		_, err := Parse([]byte(c.recipe))
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: err = %v, want %q", c.name, err, c.want)
+		}
+	}
+}
+
+func TestRemoveBuildSettingWithoutValue(t *testing.T) {
+	r, err := Parse([]byte("name: x\noperations:\n  - {op: remove-build-setting, key: A}\n  - {op: remove-build-setting, key: B, value: ~}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, op := range r.Operations {
+		if values, err := settingList(&op.Value); err != nil || values != nil {
+			t.Errorf("%s: settingList = %v, %v; want nothing", op.Key, values, err)
+		}
+	}
+}
+
+func TestBundledRecipesParse(t *testing.T) {
+	paths, err := filepath.Glob("../../recipes/*.yaml")
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no bundled recipes: %v", err)
+	}
+	for _, path := range paths {
+		if _, err := Load(path); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !unix
+
+package runner
+
+import (
+	"os"
+	"os/exec"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// signalGroup can only reach the command itself here, and only kill it.
+func signalGroup(cmd *exec.Cmd, sig os.Signal) {
+	if cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+}
@@ -0,0 +1,218 @@
+// Package runner runs long external tools such as Cocos Creator the way a CI
+// agent needs them run: output streamed line by line to the console and a
+// log, an overall and an inactivity timeout, and Jenkins' abort signals
+// forwarded to the whole process group so no helper process outlives the job.
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrTimeout is returned, wrapped, when a timeout stopped the command.
+	ErrTimeout = errors.New("runner: timed out")
+	// ErrInterrupted is returned, wrapped, when a signal or a cancelled
+	// context stopped the command.
+	ErrInterrupted = errors.New("runner: interrupted")
+)
+
+// DefaultKillGrace is how long a stopped command gets between SIGTERM and
+// SIGKILL when Options.KillGrace is zero.
+const DefaultKillGrace = 10 * time.Second
+
+// drainTimeout bounds the wait for output after the command exited, in case
+// a detached helper still holds the pipe open.
+const drainTimeout = 5 * time.Second
+
+// Options tune Run. The zero value streams nowhere and never times out.
+type Options struct {
+	// Console and Log receive every line of output; either may be nil.
+	Console io.Writer
+	Log     io.Writer
+	// OnLine is called with each line, without its newline, e.g. to feed a
+	// log parser while the command runs.
+	OnLine func(line string)
+
+	// Timeout bounds the whole run; IdleTimeout bounds the time without any
+	// output. Zero disables either.
+	Timeout     time.Duration
+	IdleTimeout time.Duration
+	// KillGrace is the delay between SIGTERM and SIGKILL.
+	KillGrace time.Duration
+	// Signals are forwarded to the process group; by default SIGINT and
+	// SIGTERM, which Jenkins sends when a build is aborted.
+	Signals []os.Signal
+}
+
+// Run starts cmd in its own process group, merges its stdout and stderr and
+// streams them line by line until it exits. When a timeout expires, ctx is
+// cancelled or one of the forwarded signals arrives, the group gets SIGTERM
+// and, after the grace period, SIGKILL. Processes the command left behind are
+// killed once it exits.
+func Run(ctx context.Context, cmd *exec.Cmd, opts Options) error {
+	if opts.KillGrace == 0 {
+		opts.KillGrace = DefaultKillGrace
+	}
+	if opts.Signals == nil {
+		opts.Signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("runner: %w", err)
+	}
+	defer pr.Close()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		pw.Close()
+		return fmt.Errorf("runner: %w", err)
+	}
+	// Only the children hold the write end now, so EOF means they all exited.
+	pw.Close()
+
+	activity := make(chan struct{}, 1)
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		stream(pr, opts, activity)
+	}()
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, opts.Signals...)
+	defer signal.Stop(signals)
+
+	var deadline <-chan time.Time
+	if opts.Timeout > 0 {
+		timer := time.NewTimer(opts.Timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	var idle <-chan time.Time
+	var idleTimer *time.Timer
+	if opts.IdleTimeout > 0 {
+		idleTimer = time.NewTimer(opts.IdleTimeout)
+		defer idleTimer.Stop()
+		idle = idleTimer.C
+	}
+
+	// The first reason to stop wins; a second signal skips the grace period.
+	var stopErr error
+	var kill <-chan time.Time
+	terminate := func(sig os.Signal, cause error) {
+		if stopErr != nil {
+			return
+		}
+		stopErr = cause
+		signalGroup(cmd, sig)
+		kill = time.After(opts.KillGrace)
+	}
+
+	var waitErr error
+wait:
+	for {
+		select {
+		case waitErr = <-exited:
+			break wait
+		case <-activity:
+			// idle is nil once the timer fired: its value was received,
+			// so there is nothing to drain and no reason to restart it.
+			if idle != nil {
+				if !idleTimer.Stop() {
+					<-idleTimer.C
+				}
+				idleTimer.Reset(opts.IdleTimeout)
+			}
+		case <-deadline:
+			terminate(syscall.SIGTERM, fmt.Errorf("%w after %s", ErrTimeout, opts.Timeout))
+		case <-idle:
+			idle = nil
+			terminate(syscall.SIGTERM, fmt.Errorf("%w: no output for %s", ErrTimeout, opts.IdleTimeout))
+		case sig := <-signals:
+			if stopErr != nil {
+				signalGroup(cmd, syscall.SIGKILL)
+				continue
+			}
+			terminate(sig, fmt.Errorf("%w by %s", ErrInterrupted, sig))
+		case <-ctx.Done():
+			terminate(syscall.SIGTERM, fmt.Errorf("%w: %v", ErrInterrupted, ctx.Err()))
+		case <-kill:
+			signalGroup(cmd, syscall.SIGKILL)
+		}
+	}
+
+	// Kill whatever the command spawned and left running.
+	signalGroup(cmd, syscall.SIGKILL)
+	select {
+	case <-drained:
+	case <-time.After(drainTimeout):
+		pr.Close()
+		<-drained
+	}
+
+	if stopErr != nil {
+		return stopErr
+	}
+	return waitErr
+}
+
+// stream copies r line by line to the outputs, signalling activity for the
+// inactivity timeout. Partial lines such as progress output count as
+// activity too, and are emitted once their newline arrives.
+func stream(r io.Reader, opts Options, activity chan<- struct{}) {
+	buf := make([]byte, 32*1024)
+	var pending []byte
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			select {
+			case activity <- struct{}{}:
+			default:
+			}
+			pending = append(pending, buf[:n]...)
+			for {
+				i := bytes.IndexByte(pending, '\n')
+				if i < 0 {
+					break
+				}
+				emit(opts, string(pending[:i+1]))
+				pending = pending[i+1:]
+			}
+		}
+		if err != nil {
+			if len(pending) > 0 {
+				emit(opts, string(pending))
+			}
+			return
+		}
+	}
+}
+
+func emit(opts Options, text string) {
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	if opts.Console != nil {
+		io.WriteString(opts.Console, text)
+	}
+	if opts.Log != nil {
+		io.WriteString(opts.Log, text)
+	}
+	if opts.OnLine != nil {
+		opts.OnLine(strings.TrimRight(text, "\r\n"))
+	}
+}
@@ -0,0 +1,88 @@
+//go:build unix
+
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// run runs script with sh and fails the test if Run does not return within
+// limit.
+func run(t *testing.T, script string, opts Options, limit time.Duration) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	opts.Log = &out
+	done := make(chan error, 1)
+	go func() { done <- Run(context.Background(), exec.Command("sh", "-c", script), opts) }()
+	select {
+	case err := <-done:
+		return out.String(), err
+	case <-time.After(limit):
+		t.Fatalf("Run still running after %s; output so far:\n%s", limit, out.String())
+		return "", nil
+	}
+}
+
+func TestIdleTimeoutThenOutputOnTerm(t *testing.T) {
+	// Silent past the idle timeout, then writes when SIGTERM arrives: the
+	// output after the idle timer fired used to block Run for good.
+	script := `trap 'echo got term; sleep 1; exit 0' TERM; sleep 10 & wait`
+	out, err := run(t, script, Options{IdleTimeout: 200 * time.Millisecond, KillGrace: 5 * time.Second}, 8*time.Second)
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want ErrTimeout", err)
+	}
+	if !strings.Contains(err.Error(), "no output") {
+		t.Errorf("err = %v, want the idle timeout", err)
+	}
+	if !strings.Contains(out, "got term") {
+		t.Errorf("output = %q, want the SIGTERM handler's line", out)
+	}
+}
+
+func TestIdleTimeoutKillGrace(t *testing.T) {
+	// Ignores SIGTERM and keeps talking: SIGKILL must follow the grace period.
+	script := `trap 'echo ignoring term' TERM; sleep 1; while :; do echo tick; sleep 0.1; done`
+	start := time.Now()
+	_, err := run(t, script, Options{IdleTimeout: 300 * time.Millisecond, KillGrace: 500 * time.Millisecond}, 8*time.Second)
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want ErrTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 4*time.Second {
+		t.Errorf("took %s, want SIGKILL after the grace period", elapsed)
+	}
+}
+
+func TestOutputResetsIdleTimeout(t *testing.T) {
+	script := `for i in 1 2 3 4 5 6; do echo line $i; sleep 0.1; done`
+	out, err := run(t, script, Options{IdleTimeout: 400 * time.Millisecond}, 8*time.Second)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if strings.Count(out, "line") != 6 {
+		t.Errorf("output = %q, want 6 lines", out)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	_, err := run(t, `while :; do echo tick; sleep 0.1; done`, Options{Timeout: 300 * time.Millisecond, KillGrace: 500 * time.Millisecond}, 8*time.Second)
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want ErrTimeout", err)
+	}
+}
+
+func TestExitStatus(t *testing.T) {
+	out, err := run(t, `echo partial; printf 'no newline'; exit 3`, Options{}, 8*time.Second)
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatalf("err = %v, want exit status 3", err)
+	}
+	if out != "partial\nno newline\n" {
+		t.Errorf("output = %q", out)
+	}
+}
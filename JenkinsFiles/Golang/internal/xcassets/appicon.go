@@ -0,0 +1,252 @@
+package xcassets
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// MarketingIdiom is the 1024×1024 App Store icon, which may not have an
+// alpha channel.
+const MarketingIdiom = "ios-marketing"
+
+// MasterSize is the side in pixels of the source icon sets are generated
+// from.
+const MasterSize = 1024
+
+// Slot identifies an app icon entry: the same slot in two sets holds the
+// same picture.
+type Slot struct {
+	Idiom string
+	Size  string
+	Scale string
+}
+
+// Slot returns the slot of an entry. An entry without a scale, as written
+// for single-size icons, is at 1x.
+func (i Image) Slot() Slot {
+	scale := i.Scale
+	if scale == "" {
+		scale = "1x"
+	}
+	return Slot{Idiom: i.Idiom, Size: i.Size, Scale: scale}
+}
+
+func (s Slot) String() string {
+	return fmt.Sprintf("%s %s@%s", s.Idiom, s.Size, s.Scale)
+}
+
+// Pixels is the width and height the slot's PNG must have, e.g. 167×167
+// for 83.5x83.5 at 2x.
+func (s Slot) Pixels() (width, height int, err error) {
+	w, h, ok := strings.Cut(s.Size, "x")
+	if !ok {
+		return 0, 0, fmt.Errorf("xcassets: size %q is not WxH", s.Size)
+	}
+	scale, err := strconv.ParseFloat(strings.TrimSuffix(s.Scale, "x"), 64)
+	if err != nil || !strings.HasSuffix(s.Scale, "x") || scale <= 0 {
+		return 0, 0, fmt.Errorf("xcassets: scale %q is not such as 2x", s.Scale)
+	}
+	points := [2]float64{}
+	for i, v := range []string{w, h} {
+		if points[i], err = strconv.ParseFloat(v, 64); err != nil || points[i] <= 0 {
+			return 0, 0, fmt.Errorf("xcassets: size %q is not WxH", s.Size)
+		}
+	}
+	return int(math.Round(points[0] * scale)), int(math.Round(points[1] * scale)), nil
+}
+
+// StandardIconSlots are the slots of a complete iPhone and iPad app icon
+// set, as Xcode lists them.
+var StandardIconSlots = []Slot{
+	{"iphone", "20x20", "2x"}, {"iphone", "20x20", "3x"},
+	{"iphone", "29x29", "2x"}, {"iphone", "29x29", "3x"},
+	{"iphone", "40x40", "2x"}, {"iphone", "40x40", "3x"},
+	{"iphone", "60x60", "2x"}, {"iphone", "60x60", "3x"},
+	{"ipad", "20x20", "1x"}, {"ipad", "20x20", "2x"},
+	{"ipad", "29x29", "1x"}, {"ipad", "29x29", "2x"},
+	{"ipad", "40x40", "1x"}, {"ipad", "40x40", "2x"},
+	{"ipad", "76x76", "1x"}, {"ipad", "76x76", "2x"},
+	{"ipad", "83.5x83.5", "2x"},
+	{MarketingIdiom, "1024x1024", "1x"},
+}
+
+// ValidateIconSet checks the app icon set folder dir: every entry must
+// name a PNG that exists with the slot's pixel size, no slot may be listed
+// twice, and the marketing icon must be opaque. It fails only when
+// Contents.json cannot be read.
+func ValidateIconSet(dir string) ([]Issue, error) {
+	contents, err := ReadContents(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	report := func(severity Severity, kind, file, format string, args ...interface{}) {
+		issues = append(issues, Issue{Severity: severity, Kind: kind, File: file, Message: fmt.Sprintf(format, args...)})
+	}
+
+	seen := map[Slot]bool{}
+	referenced := map[string]bool{ContentsFile: true}
+	for _, img := range contents.Images {
+		slot := img.Slot()
+		if seen[slot] {
+			report(Error, IssueDuplicateSlot, img.Filename, "%s is listed more than once", slot)
+		}
+		seen[slot] = true
+
+		if img.Filename == "" {
+			if _, _, err := slot.Pixels(); err == nil {
+				report(Warning, IssueEmptySlot, "", "%s has no image", slot)
+			}
+		} else {
+			referenced[img.Filename] = true
+		}
+		issues = append(issues, checkIcon(dir, img)...)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("xcassets: %w", err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() && !referenced[e.Name()] && !strings.HasPrefix(e.Name(), ".") {
+			report(Warning, IssueUnreferenced, e.Name(), "not listed in %s", ContentsFile)
+		}
+	}
+	return issues, nil
+}
+
+// checkIcon checks the image of one entry of the icon set dir: a PNG that
+// exists, with the slot's pixel size and, for the marketing icon, no alpha.
+// Empty entries are only checked for a valid size and scale.
+func checkIcon(dir string, img Image) []Issue {
+	slot := img.Slot()
+	issue := func(kind, format string, args ...interface{}) []Issue {
+		return []Issue{{Severity: Error, Kind: kind, File: img.Filename, Message: fmt.Sprintf("%s: ", slot) + fmt.Sprintf(format, args...)}}
+	}
+
+	width, height, err := slot.Pixels()
+	if err != nil {
+		return issue(IssueBadEntry, "%v", err)
+	}
+	if img.Filename == "" {
+		return nil
+	}
+	config, err := decodePNGConfig(filepath.Join(dir, img.Filename))
+	switch {
+	case os.IsNotExist(err):
+		return issue(IssueMissingFile, "file does not exist")
+	case err != nil:
+		return issue(IssueNotPNG, "%v", err)
+	}
+	var issues []Issue
+	if config.Width != width || config.Height != height {
+		issues = append(issues, issue(IssueWrongSize, "%d×%d pixels, want %d×%d", config.Width, config.Height, width, height)...)
+	}
+	if img.Idiom == MarketingIdiom && hasAlpha(config.ColorModel) {
+		issues = append(issues, issue(IssueAlpha, "has an alpha channel, which App Store Connect rejects")...)
+	}
+	return issues
+}
+
+func decodePNGConfig(path string) (image.Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return image.Config{}, err
+	}
+	defer f.Close()
+	config, err := png.DecodeConfig(f)
+	if err != nil {
+		return image.Config{}, fmt.Errorf("not a PNG: %w", err)
+	}
+	return config, nil
+}
+
+// hasAlpha reports whether a PNG with this color model stores an alpha
+// channel, a transparent color or transparent palette entries, whatever its
+// pixels are. image/png reports the first two as non-premultiplied models.
+func hasAlpha(m color.Model) bool {
+	switch m {
+	case color.NRGBAModel, color.NRGBA64Model:
+		return true
+	}
+	if palette, ok := m.(color.Palette); ok {
+		for _, c := range palette {
+			if _, _, _, a := c.RGBA(); a != 0xffff {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GeneratedIconSet is an app icon set rendered from a master image.
+type GeneratedIconSet struct {
+	Contents *Contents
+	// Files maps file names to PNG data; slots with the same pixel size
+	// share a file.
+	Files map[string][]byte
+	// Flattened is set when the master had transparent pixels, which were
+	// put over a white background.
+	Flattened bool
+}
+
+// GenerateIconSet renders every slot from master, which must be a square of
+// at least MasterSize pixels.
+func GenerateIconSet(master image.Image, slots []Slot) (*GeneratedIconSet, error) {
+	b := master.Bounds()
+	if b.Dx() != b.Dy() || b.Dx() < MasterSize {
+		return nil, fmt.Errorf("xcassets: source icon is %d×%d, want a %d×%d square", b.Dx(), b.Dy(), MasterSize, MasterSize)
+	}
+
+	src, flattened := Flatten(master, color.White)
+	set := &GeneratedIconSet{
+		Contents:  &Contents{Info: Info{Author: "xcode", Version: 1}},
+		Files:     map[string][]byte{},
+		Flattened: flattened,
+	}
+	for _, slot := range slots {
+		width, height, err := slot.Pixels()
+		if err != nil {
+			return nil, err
+		}
+		name := fmt.Sprintf("Icon-%d.png", width)
+		if width != height {
+			name = fmt.Sprintf("Icon-%dx%d.png", width, height)
+		}
+		if _, ok := set.Files[name]; !ok {
+			var buf bytes.Buffer
+			if err := png.Encode(&buf, Resize(src, width, height)); err != nil {
+				return nil, fmt.Errorf("xcassets: %w", err)
+			}
+			set.Files[name] = buf.Bytes()
+		}
+		set.Contents.Images = append(set.Contents.Images, Image{Idiom: slot.Idiom, Size: slot.Size, Scale: slot.Scale, Filename: name})
+	}
+	return set, nil
+}
+
+// WriteTo writes the set into the existing folder dir.
+func (s *GeneratedIconSet) WriteTo(dir string) error {
+	contents, err := s.Contents.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, ContentsFile), contents, 0644); err != nil {
+		return fmt.Errorf("xcassets: %w", err)
+	}
+	for name, data := range s.Files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return fmt.Errorf("xcassets: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package xcassets
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+const brokenIconSet = `{
+  "images" : [
+    {"filename" : "Icon-40.png", "idiom" : "iphone", "scale" : "2x", "size" : "20x20"},
+    {"filename" : "Icon-60.png", "idiom" : "iphone", "scale" : "3x", "size" : "20x20"},
+    {"filename" : "Missing.png", "idiom" : "iphone", "scale" : "2x", "size" : "29x29"},
+    {"filename" : "Icon-1024.png", "idiom" : "ios-marketing", "scale" : "1x", "size" : "1024x1024"}
+  ],
+  "info" : {"author" : "xcode", "version" : 1}
+}`
+
+func TestValidateIconSet(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "AppIcon"+AppIconSetExt)
+	// Translucent pixels make every PNG carry an alpha channel; only the
+	// marketing icon may not.
+	writeIconSet(t, dir, brokenIconSet, map[string]int{"Icon-40.png": 40, "Icon-60.png": 58, "Icon-1024.png": 1024}, color.NRGBA{R: 255, A: 128})
+
+	issues, err := ValidateIconSet(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, issue := range issues {
+		if issue.Severity != Error {
+			t.Errorf("%v is not an error", issue)
+		}
+		got[issue.File] = issue.Kind
+	}
+	want := map[string]string{
+		"Icon-60.png":   IssueWrongSize,
+		"Missing.png":   IssueMissingFile,
+		"Icon-1024.png": IssueAlpha,
+	}
+	if len(issues) != len(want) {
+		t.Errorf("got %d issues, want %d: %v", len(issues), len(want), issues)
+	}
+	for file, kind := range want {
+		if got[file] != kind {
+			t.Errorf("%s: got %q, want %q", file, got[file], kind)
+		}
+	}
+}
+
+func TestGenerateIconSet(t *testing.T) {
+	master := image.NewNRGBA(image.Rect(0, 0, MasterSize, MasterSize))
+	draw.Draw(master, master.Bounds(), image.NewUniform(color.NRGBA{B: 255, A: 255}), image.Point{}, draw.Src)
+	// One transparent corner gets flattened onto white.
+	master.Set(0, 0, color.NRGBA{})
+
+	set, err := GenerateIconSet(master, StandardIconSlots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !set.Flattened {
+		t.Error("Flattened not set for a master with transparent pixels")
+	}
+	if len(set.Contents.Images) != len(StandardIconSlots) {
+		t.Fatalf("got %d entries, want %d", len(set.Contents.Images), len(StandardIconSlots))
+	}
+	used := map[string]bool{}
+	for i, img := range set.Contents.Images {
+		slot := StandardIconSlots[i]
+		if img.Slot() != slot {
+			t.Errorf("entry %d is %s, want %s", i, img.Slot(), slot)
+		}
+		data, ok := set.Files[img.Filename]
+		if !ok {
+			t.Errorf("%s: no file %s", slot, img.Filename)
+			continue
+		}
+		used[img.Filename] = true
+		config, err := png.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: %v", slot, err)
+		}
+		width, height, _ := slot.Pixels()
+		if config.Width != width || config.Height != height {
+			t.Errorf("%s: %s is %d×%d, want %d×%d", slot, img.Filename, config.Width, config.Height, width, height)
+		}
+	}
+	var unused []string
+	for name := range set.Files {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+	if len(unused) > 0 {
+		t.Errorf("files not listed in Contents.json: %v", unused)
+	}
+
+	// Written out, the set passes validation, opaque marketing icon included.
+	dir := filepath.Join(t.TempDir(), "AppIcon"+AppIconSetExt)
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.WriteTo(dir); err != nil {
+		t.Fatal(err)
+	}
+	issues, err := ValidateIconSet(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, issue := range issues {
+		t.Errorf("generated set: %v", issue)
+	}
+
+	if _, err := GenerateIconSet(image.NewNRGBA(image.Rect(0, 0, 512, 512)), StandardIconSlots); err == nil {
+		t.Error("GenerateIconSet accepted a 512×512 master")
+	}
+}
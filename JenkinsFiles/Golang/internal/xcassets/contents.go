@@ -0,0 +1,133 @@
+// Package xcassets reads, checks and writes the sets of an Xcode asset
+// catalog (Images.xcassets), starting with app icons.
+package xcassets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// ContentsFile describes the entries of a set.
+const ContentsFile = "Contents.json"
+
+// Contents is a set's Contents.json. Keys this package does not model are
+// kept in Other so rewriting the file does not lose them.
+type Contents struct {
+	Images []Image
+	Info   Info
+	Other  map[string]json.RawMessage
+}
+
+// Info is the "info" block Xcode writes in every Contents.json.
+type Info struct {
+	Author  string `json:"author"`
+	Version int    `json:"version"`
+}
+
+// Image is one entry of "images". Size and Scale are empty for sets that
+// have no such slots, such as plain image sets without a size.
+type Image struct {
+	Idiom    string
+	Size     string
+	Scale    string
+	Filename string
+	// Other keeps the remaining keys, e.g. role, subtype, platform or
+	// appearances.
+	Other map[string]json.RawMessage
+}
+
+// ReadContents reads the Contents.json of the set folder dir.
+func ReadContents(dir string) (*Contents, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ContentsFile))
+	if err != nil {
+		return nil, fmt.Errorf("xcassets: %w", err)
+	}
+	var c Contents
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("xcassets: %s: %w", filepath.Join(dir, ContentsFile), err)
+	}
+	return &c, nil
+}
+
+func (c *Contents) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if images, ok := raw["images"]; ok {
+		if err := json.Unmarshal(images, &c.Images); err != nil {
+			return fmt.Errorf("images: %w", err)
+		}
+		delete(raw, "images")
+	}
+	if info, ok := raw["info"]; ok {
+		if err := json.Unmarshal(info, &c.Info); err != nil {
+			return fmt.Errorf("info: %w", err)
+		}
+		delete(raw, "info")
+	}
+	c.Other = raw
+	return nil
+}
+
+func (c *Contents) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{}
+	for k, v := range c.Other {
+		m[k] = v
+	}
+	if c.Images != nil {
+		m["images"] = c.Images
+	}
+	m["info"] = c.Info
+	return json.Marshal(m)
+}
+
+func (i *Image) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for key, field := range map[string]*string{"idiom": &i.Idiom, "size": &i.Size, "scale": &i.Scale, "filename": &i.Filename} {
+		if v, ok := raw[key]; ok {
+			if err := json.Unmarshal(v, field); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			delete(raw, key)
+		}
+	}
+	i.Other = raw
+	return nil
+}
+
+func (i Image) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{}
+	for k, v := range i.Other {
+		m[k] = v
+	}
+	for key, value := range map[string]string{"idiom": i.Idiom, "size": i.Size, "scale": i.Scale, "filename": i.Filename} {
+		if value != "" {
+			m[key] = value
+		}
+	}
+	return json.Marshal(m)
+}
+
+// keyColon matches the ": " after an object key at the start of a line.
+var keyColon = regexp.MustCompile(`(?m)^(\s*"(?:[^"\\]|\\.)*"): `)
+
+// Marshal encodes the contents the way Xcode saves them: sorted keys,
+// two-space indentation and a space before each colon.
+func (c *Contents) Marshal() ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(c); err != nil {
+		return nil, err
+	}
+	return keyColon.ReplaceAll(b.Bytes(), []byte("$1 : ")), nil
+}
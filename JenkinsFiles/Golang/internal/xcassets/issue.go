@@ -0,0 +1,46 @@
+package xcassets
+
+import "fmt"
+
+// Severity ranks validation issues.
+type Severity int
+
+const (
+	Warning Severity = iota
+	Error
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// Issue kinds reported by the validators.
+const (
+	IssueBadEntry      = "bad-entry"
+	IssueDuplicateSlot = "duplicate-slot"
+	IssueEmptySlot     = "empty-slot"
+	IssueMissingFile   = "missing-file"
+	IssueNotPNG        = "not-png"
+	IssueWrongSize     = "wrong-size"
+	IssueAlpha         = "alpha"
+	IssueUnreferenced  = "unreferenced"
+)
+
+// Issue is one problem found in a set.
+type Issue struct {
+	Severity Severity
+	Kind     string
+	// File is the image concerned, if any.
+	File    string
+	Message string
+}
+
+func (i Issue) String() string {
+	if i.File == "" {
+		return fmt.Sprintf("%s [%s] %s", i.Severity, i.Kind, i.Message)
+	}
+	return fmt.Sprintf("%s [%s] %s: %s", i.Severity, i.Kind, i.File, i.Message)
+}
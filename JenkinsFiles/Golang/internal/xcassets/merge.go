@@ -0,0 +1,166 @@
+package xcassets
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MergedIconSet is the slot-by-slot union of several app icon sets.
+type MergedIconSet struct {
+	Contents *Contents
+	// Files maps each file name of the merged set to the file it is copied
+	// from.
+	Files map[string]string
+	// Choices says where each filled slot's image comes from, in the order
+	// of Contents.
+	Choices []SlotChoice
+	// Missing lists the slots no set fills: listed slots left empty and
+	// standard slots nobody lists.
+	Missing []Slot
+}
+
+// SlotChoice is the image picked for one slot.
+type SlotChoice struct {
+	Slot Slot
+	// Source is the icon set the image comes from and File its name there.
+	Source string
+	File   string
+	// Issues are the problems of the picked image, when no set had a valid
+	// one for the slot.
+	Issues []Issue
+}
+
+// MergeIconSets reconciles the app icon sets in dirs, most preferred
+// first, by slot (idiom, size and scale). Each slot takes the image of the
+// first set where it is valid, else the first set that has one at all, so
+// a slot only one set provides, such as an iPad size, is kept. Slots keep
+// the order they first appear in.
+func MergeIconSets(dirs ...string) (*MergedIconSet, error) {
+	type candidate struct {
+		dir string
+		img Image
+	}
+	var order []Slot
+	candidates := map[Slot][]candidate{}
+	for _, dir := range dirs {
+		contents, err := ReadContents(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, img := range contents.Images {
+			slot := img.Slot()
+			if _, ok := candidates[slot]; !ok {
+				order = append(order, slot)
+			}
+			candidates[slot] = append(candidates[slot], candidate{dir, img})
+		}
+	}
+
+	m := &MergedIconSet{
+		Contents: &Contents{Info: Info{Author: "xcode", Version: 1}},
+		Files:    map[string]string{},
+	}
+	for _, slot := range order {
+		var best *candidate
+		var bestIssues []Issue
+		for i, c := range candidates[slot] {
+			if c.img.Filename == "" {
+				continue
+			}
+			issues := checkIcon(c.dir, c.img)
+			if len(issues) == 0 {
+				best, bestIssues = &candidates[slot][i], nil
+				break
+			}
+			if best == nil {
+				best, bestIssues = &candidates[slot][i], issues
+			}
+		}
+
+		if best == nil {
+			// Keep the empty slot as the first set lists it.
+			m.Contents.Images = append(m.Contents.Images, candidates[slot][0].img)
+			m.Missing = append(m.Missing, slot)
+			continue
+		}
+		img := best.img
+		img.Filename = m.addFile(filepath.Join(best.dir, best.img.Filename))
+		m.Contents.Images = append(m.Contents.Images, img)
+		m.Choices = append(m.Choices, SlotChoice{Slot: slot, Source: best.dir, File: best.img.Filename, Issues: bestIssues})
+	}
+
+	for _, slot := range StandardIconSlots {
+		if _, ok := candidates[slot]; !ok {
+			m.Missing = append(m.Missing, slot)
+		}
+	}
+	return m, nil
+}
+
+// addFile registers source in the merged set and returns its name there.
+// Sets often use the same names for different pictures, so a name already
+// taken by a file with other content gets a numbered suffix.
+func (m *MergedIconSet) addFile(source string) string {
+	base := filepath.Base(source)
+	ext := filepath.Ext(base)
+	name := base
+	for n := 2; ; n++ {
+		existing, taken := m.Files[name]
+		if !taken {
+			break
+		}
+		if existing == source || sameFile(existing, source) {
+			return name
+		}
+		name = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(base, ext), n, ext)
+	}
+	m.Files[name] = source
+	return name
+}
+
+// WriteTo writes the merged set into the existing folder dir.
+func (m *MergedIconSet) WriteTo(dir string) error {
+	contents, err := m.Contents.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, ContentsFile), contents, 0644); err != nil {
+		return fmt.Errorf("xcassets: %w", err)
+	}
+	for name, source := range m.Files {
+		if err := copyFile(source, filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("xcassets: %w", err)
+		}
+	}
+	return nil
+}
+
+func sameFile(a, b string) bool {
+	da, err := os.ReadFile(a)
+	if err != nil {
+		return false
+	}
+	db, err := os.ReadFile(b)
+	return err == nil && bytes.Equal(da, db)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
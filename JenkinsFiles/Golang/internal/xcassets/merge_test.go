@@ -0,0 +1,121 @@
+package xcassets
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeIconSet writes an app icon set whose images are filled PNGs of the
+// given side, keyed by file name.
+func writeIconSet(t *testing.T, dir, contents string, sides map[string]int, fill color.Color) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ContentsFile), []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for name, side := range sides {
+		img := image.NewRGBA(image.Rect(0, 0, side, side))
+		draw.Draw(img, img.Bounds(), image.NewUniform(fill), image.Point{}, draw.Src)
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := png.Encode(f, img); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+}
+
+func TestMergeIconSets(t *testing.T) {
+	root := t.TempDir()
+	unity := filepath.Join(root, "Unity", "AppIcon.appiconset")
+	cocos := filepath.Join(root, "Cocos", "AppIcon.appiconset")
+	writeIconSet(t, unity, `{"images": [
+		{"idiom": "iphone", "size": "60x60", "scale": "2x", "filename": "Icon.png"},
+		{"idiom": "iphone", "size": "60x60", "scale": "3x", "filename": "Icon-180.png"},
+		{"idiom": "iphone", "size": "40x40", "scale": "2x"}
+	], "info": {"author": "xcode", "version": 1}}`,
+		map[string]int{"Icon.png": 120, "Icon-180.png": 90}, color.White)
+	writeIconSet(t, cocos, `{"images": [
+		{"idiom": "iphone", "size": "60x60", "scale": "3x", "filename": "Icon-60@3x.png"},
+		{"idiom": "ipad", "size": "76x76", "scale": "2x", "filename": "Icon.png"}
+	], "info": {"author": "xcode", "version": 1}}`,
+		map[string]int{"Icon-60@3x.png": 180, "Icon.png": 152}, color.Black)
+
+	m, err := MergeIconSets(unity, cocos)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		slot   Slot
+		source string
+		file   string
+		merged string
+	}{
+		// Unity is preferred where its image is valid...
+		{Slot{"iphone", "60x60", "2x"}, unity, "Icon.png", "Icon.png"},
+		// ...Cocos fills the slot where Unity's has the wrong size...
+		{Slot{"iphone", "60x60", "3x"}, cocos, "Icon-60@3x.png", "Icon-60@3x.png"},
+		// ...and keeps the slots only it has, renamed off Unity's Icon.png.
+		{Slot{"ipad", "76x76", "2x"}, cocos, "Icon.png", "Icon-2.png"},
+	}
+	if len(m.Choices) != len(want) {
+		t.Fatalf("got %d choices %+v, want %d", len(m.Choices), m.Choices, len(want))
+	}
+	for i, w := range want {
+		c := m.Choices[i]
+		if c.Slot != w.slot || c.Source != w.source || c.File != w.file || len(c.Issues) != 0 {
+			t.Errorf("choice %d = %+v, want %s from %s/%s", i, c, w.slot, w.source, w.file)
+		}
+		if got := m.Files[w.merged]; got != filepath.Join(w.source, w.file) {
+			t.Errorf("%s copied from %s, want %s/%s", w.merged, got, w.source, w.file)
+		}
+	}
+
+	var filenames []string
+	for _, img := range m.Contents.Images {
+		filenames = append(filenames, img.Slot().String()+"="+img.Filename)
+	}
+	wantNames := []string{"iphone 60x60@2x=Icon.png", "iphone 60x60@3x=Icon-60@3x.png", "iphone 40x40@2x=", "ipad 76x76@2x=Icon-2.png"}
+	if len(filenames) != len(wantNames) {
+		t.Fatalf("merged images %q, want %q", filenames, wantNames)
+	}
+	for i := range wantNames {
+		if filenames[i] != wantNames[i] {
+			t.Errorf("merged images %q, want %q", filenames, wantNames)
+			break
+		}
+	}
+
+	// The listed empty slot comes first, then the standard slots nobody
+	// lists: all but the four above.
+	if len(m.Missing) != 1+len(StandardIconSlots)-4 || m.Missing[0] != (Slot{"iphone", "40x40", "2x"}) {
+		t.Errorf("Missing = %v", m.Missing)
+	}
+
+	out := filepath.Join(root, "Merged.appiconset")
+	if err := os.MkdirAll(out, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.WriteTo(out); err != nil {
+		t.Fatal(err)
+	}
+	issues, err := ValidateIconSet(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, issue := range issues {
+		if issue.Severity == Error {
+			t.Errorf("merged set: %s", issue)
+		}
+	}
+}
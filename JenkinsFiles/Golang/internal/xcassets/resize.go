@@ -0,0 +1,142 @@
+package xcassets
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Flatten returns img drawn over bg and whether img had any transparent
+// pixel. The result is opaque, so it encodes as a PNG without alpha.
+func Flatten(img image.Image, bg color.Color) (*image.NRGBA, bool) {
+	b := img.Bounds()
+	transparent := false
+	if o, ok := img.(interface{ Opaque() bool }); !ok || !o.Opaque() {
+		for y := b.Min.Y; y < b.Max.Y && !transparent; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+					transparent = true
+					break
+				}
+			}
+		}
+	}
+	out := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(out, out.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	draw.Draw(out, out.Bounds(), img, b.Min, draw.Over)
+	return out, transparent
+}
+
+// Resize scales img to width×height with a Catmull-Rom filter, widened
+// when shrinking so every source pixel contributes, which keeps small icons
+// sharp without aliasing.
+func Resize(img image.Image, width, height int) *image.NRGBA {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+
+	// Premultiplied RGBA, so transparent pixels do not bleed their color.
+	src := make([]float64, sw*sh*4)
+	for y := 0; y < sh; y++ {
+		for x := 0; x < sw; x++ {
+			r, g, bl, a := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			i := (y*sw + x) * 4
+			src[i], src[i+1], src[i+2], src[i+3] = float64(r), float64(g), float64(bl), float64(a)
+		}
+	}
+
+	// Rows first, then columns.
+	tmp := make([]float64, width*sh*4)
+	for x, taps := range filterTaps(sw, width) {
+		for y := 0; y < sh; y++ {
+			var sum [4]float64
+			for _, t := range taps {
+				i := (y*sw + t.index) * 4
+				for c := 0; c < 4; c++ {
+					sum[c] += src[i+c] * t.weight
+				}
+			}
+			copy(tmp[(y*width+x)*4:], sum[:])
+		}
+	}
+	out := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y, taps := range filterTaps(sh, height) {
+		for x := 0; x < width; x++ {
+			var sum [4]float64
+			for _, t := range taps {
+				i := (t.index*width + x) * 4
+				for c := 0; c < 4; c++ {
+					sum[c] += tmp[i+c] * t.weight
+				}
+			}
+			out.SetNRGBA(x, y, unpremultiply(sum))
+		}
+	}
+	return out
+}
+
+// tap is one source pixel's share of a destination pixel.
+type tap struct {
+	index  int
+	weight float64
+}
+
+// filterTaps returns, for each of the dst pixels along one axis, the
+// source pixels and normalized weights that make it up.
+func filterTaps(src, dst int) [][]tap {
+	scale := float64(src) / float64(dst)
+	stretch := math.Max(scale, 1)
+	support := 2 * stretch
+	taps := make([][]tap, dst)
+	for i := range taps {
+		center := (float64(i)+0.5)*scale - 0.5
+		var total float64
+		for j := int(math.Ceil(center - support)); j <= int(math.Floor(center+support)); j++ {
+			w := catmullRom((float64(j) - center) / stretch)
+			if w == 0 {
+				continue
+			}
+			// Clamp at the edges.
+			k := j
+			if k < 0 {
+				k = 0
+			} else if k >= src {
+				k = src - 1
+			}
+			taps[i] = append(taps[i], tap{k, w})
+			total += w
+		}
+		for j := range taps[i] {
+			taps[i][j].weight /= total
+		}
+	}
+	return taps
+}
+
+func catmullRom(x float64) float64 {
+	x = math.Abs(x)
+	switch {
+	case x < 1:
+		return (1.5*x-2.5)*x*x + 1
+	case x < 2:
+		return ((-0.5*x+2.5)*x-4)*x + 2
+	}
+	return 0
+}
+
+// unpremultiply turns a premultiplied 16-bit sum back into an 8-bit
+// non-premultiplied color, clamping the filter's overshoot.
+func unpremultiply(c [4]float64) color.NRGBA {
+	a := clamp(c[3])
+	if a == 0 {
+		return color.NRGBA{}
+	}
+	channel := func(v float64) uint8 {
+		return uint8(clamp(v*0xffff/a)/0x101 + 0.5)
+	}
+	return color.NRGBA{R: channel(c[0]), G: channel(c[1]), B: channel(c[2]), A: uint8(a/0x101 + 0.5)}
+}
+
+func clamp(v float64) float64 {
+	return math.Max(0, math.Min(0xffff, v))
+}
@@ -0,0 +1,202 @@
+package xcassets
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Set folder extensions with checks of their own.
+const (
+	AppIconSetExt  = ".appiconset"
+	ImageSetExt    = ".imageset"
+	LaunchImageExt = ".launchimage"
+	ColorSetExt    = ".colorset"
+)
+
+// ValidateSet checks the set folder dir according to its kind: app icon
+// sets as ValidateIconSet does; image and launch image sets for files that
+// exist, entries listed twice and, for image sets, scales whose pixel sizes
+// disagree; color sets for well-formed colors. Every set is checked for
+// files Contents.json does not list. It fails only when Contents.json
+// cannot be read.
+func ValidateSet(dir string) ([]Issue, error) {
+	if filepath.Ext(dir) == AppIconSetExt {
+		return ValidateIconSet(dir)
+	}
+	contents, err := ReadContents(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	report := func(severity Severity, kind, file, format string, args ...interface{}) {
+		issues = append(issues, Issue{Severity: severity, Kind: kind, File: file, Message: fmt.Sprintf(format, args...)})
+	}
+
+	seen := map[string]bool{}
+	referenced := map[string]bool{ContentsFile: true}
+	// Pixel width of one point per variant, for image sets.
+	pointWidths := map[string]float64{}
+	for _, img := range contents.Images {
+		key := img.variant()
+		if seen[key] {
+			report(Error, IssueDuplicateSlot, img.Filename, "%s is listed more than once", key)
+		}
+		seen[key] = true
+		if img.Filename == "" {
+			continue
+		}
+		referenced[img.Filename] = true
+
+		path := filepath.Join(dir, img.Filename)
+		if _, err := os.Stat(path); err != nil {
+			report(Error, IssueMissingFile, img.Filename, "%s: file does not exist", key)
+			continue
+		}
+		if filepath.Ext(dir) != ImageSetExt || !strings.EqualFold(filepath.Ext(img.Filename), ".png") {
+			continue
+		}
+		scale, err := strconv.ParseFloat(strings.TrimSuffix(img.Slot().Scale, "x"), 64)
+		if err != nil || scale <= 0 {
+			report(Error, IssueBadEntry, img.Filename, "%s: scale %q is not such as 2x", key, img.Scale)
+			continue
+		}
+		config, err := decodePNGConfig(path)
+		if err != nil {
+			report(Error, IssueNotPNG, img.Filename, "%s: %v", key, err)
+			continue
+		}
+		// Scales of one idiom and appearance must be the same picture.
+		base := strings.TrimSuffix(key, " @"+img.Slot().Scale)
+		width := float64(config.Width) / scale
+		if w, ok := pointWidths[base]; ok && w != width {
+			report(Warning, IssueWrongSize, img.Filename, "%s: %d pixels wide is %g points, other scales are %g", key, config.Width, width, w)
+		} else {
+			pointWidths[base] = width
+		}
+	}
+
+	if filepath.Ext(dir) == ColorSetExt {
+		issues = append(issues, checkColors(contents)...)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("xcassets: %w", err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() && !referenced[e.Name()] && !strings.HasPrefix(e.Name(), ".") {
+			report(Warning, IssueUnreferenced, e.Name(), "not listed in %s", ContentsFile)
+		}
+	}
+	return issues, nil
+}
+
+// variant names what an image set or launch image entry is for, e.g.
+// "iphone 736h portrait @3x"; two entries of a set may not share it.
+func (i Image) variant() string {
+	parts := []string{i.Idiom}
+	for _, key := range []string{"subtype", "orientation", "extent", "minimum-system-version", "appearances"} {
+		if raw, ok := i.Other[key]; ok {
+			var s string
+			if json.Unmarshal(raw, &s) != nil {
+				s = string(raw)
+			}
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " ") + " @" + i.Slot().Scale
+}
+
+// colorEntry is an entry of a color set's "colors".
+type colorEntry struct {
+	Idiom string `json:"idiom"`
+	Color *struct {
+		ColorSpace string            `json:"color-space"`
+		Components map[string]string `json:"components"`
+	} `json:"color"`
+}
+
+// colorComponents lists the components each color space needs; spaces not
+// listed are RGB.
+var colorComponents = map[string][]string{
+	"gray-gamma-22": {"white", "alpha"},
+	"extended-gray": {"white", "alpha"},
+}
+
+// extendedColorSpaces may go below 0 and past 1 to reach colors outside
+// sRGB, so their color components are not range-checked.
+var extendedColorSpaces = map[string]bool{
+	"extended-srgb":        true,
+	"extended-linear-srgb": true,
+	"extended-gray":        true,
+}
+
+// checkColors checks that every color of a color set has a color space
+// and the components that space needs, in range unless it is extended.
+func checkColors(contents *Contents) []Issue {
+	var issues []Issue
+	report := func(format string, args ...interface{}) {
+		issues = append(issues, Issue{Severity: Error, Kind: IssueBadEntry, Message: fmt.Sprintf(format, args...)})
+	}
+
+	raw, ok := contents.Other["colors"]
+	if !ok {
+		report("no colors")
+		return issues
+	}
+	var colors []colorEntry
+	if err := json.Unmarshal(raw, &colors); err != nil {
+		report("colors: %v", err)
+		return issues
+	}
+	for i, c := range colors {
+		name := fmt.Sprintf("color %d (%s)", i+1, c.Idiom)
+		if c.Color == nil {
+			// An idiom entry without a color falls back to universal.
+			continue
+		}
+		space := c.Color.ColorSpace
+		if space == "" {
+			report("%s has no color-space", name)
+		}
+		components, ok := colorComponents[space]
+		if !ok {
+			components = []string{"red", "green", "blue", "alpha"}
+		}
+		for _, component := range components {
+			v, ok := c.Color.Components[component]
+			if !ok {
+				report("%s has no %s component", name, component)
+				continue
+			}
+			if !validComponent(component, v, extendedColorSpaces[space]) {
+				report("%s: %s %q is out of range", name, component, v)
+			}
+		}
+	}
+	return issues
+}
+
+// validComponent accepts 0x00-0xFF and numbers from 0 to 255 for color
+// components, which also covers 0-1 floats, and 0 to 1 for alpha. In an
+// extended color space any number is a valid color component; alpha keeps
+// its range.
+func validComponent(component, v string, extended bool) bool {
+	if hex := strings.TrimPrefix(strings.ToLower(v), "0x"); hex != strings.ToLower(v) {
+		_, err := strconv.ParseUint(hex, 16, 8)
+		return err == nil && component != "alpha"
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return false
+	}
+	if component == "alpha" {
+		return f >= 0 && f <= 1
+	}
+	return extended || (f >= 0 && f <= 255)
+}
@@ -0,0 +1,52 @@
+package xcassets
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateColorSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		color string
+		bad   int
+	}{
+		{"srgb floats", `"color-space": "srgb", "components": {"red": "1.000", "green": "0.500", "blue": "0.000", "alpha": "1.000"}`, 0},
+		{"srgb hex", `"color-space": "display-p3", "components": {"red": "0xFF", "green": "0x80", "blue": "0x00", "alpha": "0.500"}`, 0},
+		{"srgb negative", `"color-space": "srgb", "components": {"red": "-0.100", "green": "0.500", "blue": "0.000", "alpha": "1.000"}`, 1},
+		{"srgb missing blue", `"color-space": "srgb", "components": {"red": "1.000", "green": "0.500", "alpha": "1.000"}`, 1},
+		{"gray", `"color-space": "gray-gamma-22", "components": {"white": "0.250", "alpha": "1.000"}`, 0},
+		{"gray without white", `"color-space": "gray-gamma-22", "components": {"red": "0.250", "green": "0.250", "blue": "0.250", "alpha": "1.000"}`, 1},
+		{"extended srgb", `"color-space": "extended-srgb", "components": {"red": "-0.125", "green": "1.250", "blue": "0.000", "alpha": "1.000"}`, 0},
+		{"extended linear srgb", `"color-space": "extended-linear-srgb", "components": {"red": "1.500", "green": "-0.500", "blue": "300", "alpha": "1.000"}`, 0},
+		{"extended alpha", `"color-space": "extended-srgb", "components": {"red": "1.000", "green": "1.000", "blue": "1.000", "alpha": "1.500"}`, 1},
+		{"extended not a number", `"color-space": "extended-srgb", "components": {"red": "bright", "green": "1.000", "blue": "1.000", "alpha": "1.000"}`, 1},
+		{"no color space", `"components": {"red": "1.000", "green": "1.000", "blue": "1.000", "alpha": "1.000"}`, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "Tint"+ColorSetExt)
+			if err := os.Mkdir(dir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			contents := fmt.Sprintf(`{"colors": [{"idiom": "universal", "color": {%s}}], "info": {"author": "xcode", "version": 1}}`, tt.color)
+			if err := os.WriteFile(filepath.Join(dir, ContentsFile), []byte(contents), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			issues, err := ValidateSet(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(issues) != tt.bad {
+				t.Errorf("got %d issues, want %d: %v", len(issues), tt.bad, issues)
+			}
+			for _, issue := range issues {
+				if issue.Kind != IssueBadEntry || issue.Severity != Error {
+					t.Errorf("unexpected issue %v", issue)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package xcworkspace
+
+import (
+	"bytes"
+	"strings"
+)
+
+// indent is Xcode's indentation step in workspace files.
+const indent = "   "
+
+// Marshal encodes the workspace the way Xcode writes it, so saving an
+// unchanged workspace leaves the file as Xcode left it:
+//
+//	<?xml version="1.0" encoding="UTF-8"?>
+//	<Workspace
+//	   version = "1.0">
+//	   <FileRef
+//	      location = "group:Game.xcodeproj">
+//	   </FileRef>
+//	</Workspace>
+func (w *Workspace) Marshal() []byte {
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	writeNode(&b, w.root, 0)
+	return b.Bytes()
+}
+
+func writeNode(b *bytes.Buffer, n *Node, depth int) {
+	pad := strings.Repeat(indent, depth)
+	switch n.Kind {
+	case CommentNode:
+		b.WriteString(pad + "<!--" + n.Text + "-->\n")
+		return
+	case TextNode:
+		b.WriteString(pad + escape(strings.TrimSpace(n.Text)) + "\n")
+		return
+	}
+
+	b.WriteString(pad + "<" + n.Name)
+	for _, a := range n.Attrs {
+		name := a.Name.Local
+		if a.Name.Space != "" {
+			name = a.Name.Space + ":" + name
+		}
+		b.WriteString("\n" + pad + indent + name + ` = "` + escape(a.Value) + `"`)
+	}
+	b.WriteString(">\n")
+	for _, child := range n.Children {
+		writeNode(b, child, depth+1)
+	}
+	b.WriteString(pad + "</" + n.Name + ">\n")
+}
+
+var escaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;",
+	"\n", "&#10;",
+	"\t", "&#9;",
+)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
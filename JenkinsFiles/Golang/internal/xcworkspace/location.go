@@ -0,0 +1,75 @@
+// Package xcworkspace reads and edits Xcode workspaces
+// (contents.xcworkspacedata).
+package xcworkspace
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Location kinds of the location attribute of FileRef and Group elements.
+const (
+	// KindAbsolute is an absolute path.
+	KindAbsolute = "absolute"
+	// KindGroup is relative to the enclosing group, or to the folder holding
+	// the .xcworkspace at the top level.
+	KindGroup = "group"
+	// KindContainer is relative to the folder holding the .xcworkspace.
+	KindContainer = "container"
+	// KindSelf is relative to the workspace's own container, as used by the
+	// project.xcworkspace embedded in an .xcodeproj.
+	KindSelf = "self"
+	// KindDeveloper is relative to the Xcode developer directory.
+	KindDeveloper = "developer"
+)
+
+// Location is a parsed location attribute, e.g. "group:../Unity/Unity-iPhone.xcodeproj".
+type Location struct {
+	Kind string
+	Path string
+}
+
+// ParseLocation splits a location attribute into its kind and path.
+func ParseLocation(s string) (Location, error) {
+	kind, path, ok := strings.Cut(s, ":")
+	if !ok {
+		return Location{}, fmt.Errorf("xcworkspace: location %q has no kind", s)
+	}
+	switch kind {
+	case KindAbsolute, KindGroup, KindContainer, KindSelf, KindDeveloper:
+		return Location{Kind: kind, Path: path}, nil
+	}
+	return Location{}, fmt.Errorf("xcworkspace: location %q has unknown kind %q", s, kind)
+}
+
+func (l Location) String() string {
+	return l.Kind + ":" + l.Path
+}
+
+// Resolve returns the absolute path l points at. containerDir is the folder
+// holding the .xcworkspace and groupDir the resolved folder of the enclosing
+// group (containerDir at the top level). Developer locations cannot be
+// resolved without Xcode and report false.
+func (l Location) Resolve(containerDir, groupDir string) (string, bool) {
+	switch l.Kind {
+	case KindAbsolute:
+		return filepath.Clean(l.Path), true
+	case KindGroup:
+		return filepath.Join(groupDir, l.Path), true
+	case KindContainer, KindSelf:
+		return filepath.Join(containerDir, l.Path), true
+	}
+	return "", false
+}
+
+// Relative returns the group: location of the absolute path target as seen
+// from groupDir, which keeps the workspace valid when its folder is moved
+// or copied along with the projects.
+func Relative(target, groupDir string) (Location, error) {
+	rel, err := filepath.Rel(groupDir, target)
+	if err != nil {
+		return Location{}, fmt.Errorf("xcworkspace: %w", err)
+	}
+	return Location{Kind: KindGroup, Path: filepath.ToSlash(rel)}, nil
+}
@@ -0,0 +1,386 @@
+package xcworkspace
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ContentsFile is the file inside an .xcworkspace bundle holding its items.
+const ContentsFile = "contents.xcworkspacedata"
+
+// Element names Xcode writes in a workspace.
+const (
+	ElementWorkspace = "Workspace"
+	ElementFileRef   = "FileRef"
+	ElementGroup     = "Group"
+)
+
+// NodeKind tells elements from the comments and text kept for round-trips.
+type NodeKind int
+
+const (
+	ElementNode NodeKind = iota
+	CommentNode
+	TextNode
+)
+
+// Node is one item of the workspace document. FileRef and Group elements
+// are edited through Workspace; every other element, attribute, comment and
+// text is kept as read so saving does not lose what Xcode or another tool
+// put there.
+type Node struct {
+	Kind     NodeKind
+	Name     string
+	Attrs    []xml.Attr
+	Children []*Node
+	// Text is the content of comment and text nodes.
+	Text string
+}
+
+// Attr returns the value of the attribute name, or "".
+func (n *Node) Attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name && a.Name.Space == "" {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// SetAttr sets an attribute, keeping its position when it exists.
+func (n *Node) SetAttr(name, value string) {
+	for i, a := range n.Attrs {
+		if a.Name.Local == name && a.Name.Space == "" {
+			n.Attrs[i].Value = value
+			return
+		}
+	}
+	n.Attrs = append(n.Attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+}
+
+func (n *Node) isElement(name string) bool {
+	return n.Kind == ElementNode && n.Name == name
+}
+
+// Workspace is a parsed contents.xcworkspacedata.
+type Workspace struct {
+	// Path is the .xcworkspace bundle; relative locations start from its
+	// parent folder.
+	Path string
+	root *Node
+}
+
+// New returns an empty workspace for the bundle at path.
+func New(path string) *Workspace {
+	root := &Node{Kind: ElementNode, Name: ElementWorkspace}
+	root.SetAttr("version", "1.0")
+	return &Workspace{Path: path, root: root}
+}
+
+// Load reads the workspace bundle at path. The path of its contents file is
+// accepted too.
+func Load(path string) (*Workspace, error) {
+	if filepath.Base(path) == ContentsFile {
+		path = filepath.Dir(path)
+	}
+	data, err := os.ReadFile(filepath.Join(path, ContentsFile))
+	if err != nil {
+		return nil, fmt.Errorf("xcworkspace: %w", err)
+	}
+	w, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("xcworkspace: %s: %w", path, err)
+	}
+	w.Path = path
+	return w, nil
+}
+
+// Parse reads workspace XML; Path is left empty.
+func Parse(data []byte) (*Workspace, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var root *Node
+	var stack []*Node
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var node *Node
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node = &Node{Kind: ElementNode, Name: t.Name.Local, Attrs: append([]xml.Attr(nil), t.Attr...)}
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+			continue
+		case xml.Comment:
+			node = &Node{Kind: CommentNode, Text: string(t)}
+		case xml.CharData:
+			// Indentation is regenerated on save.
+			if strings.TrimSpace(string(t)) == "" {
+				continue
+			}
+			node = &Node{Kind: TextNode, Text: string(t)}
+		default:
+			// The XML declaration is always written back.
+			continue
+		}
+
+		if len(stack) > 0 {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, node)
+		} else if node.Kind == ElementNode {
+			if root != nil {
+				return nil, errors.New("more than one root element")
+			}
+			root = node
+		}
+		if node.Kind == ElementNode {
+			stack = append(stack, node)
+		}
+	}
+	if root == nil || root.Name != ElementWorkspace {
+		return nil, errors.New("no Workspace element")
+	}
+	return &Workspace{root: root}, nil
+}
+
+// Root returns the Workspace element.
+func (w *Workspace) Root() *Node {
+	return w.root
+}
+
+// ContainerDir is the folder holding the bundle, which container: and
+// top-level group: locations start from.
+func (w *Workspace) ContainerDir() string {
+	return filepath.Dir(w.Path)
+}
+
+// Ref is a FileRef with where it sits and what it points at.
+type Ref struct {
+	Node *Node
+	// Parent is the Workspace or Group element holding Node.
+	Parent *Node
+	// Groups are the names of the enclosing groups, outermost first.
+	Groups   []string
+	Location Location
+	// Path is the absolute path the location resolves to, or "" when it
+	// cannot be resolved.
+	Path string
+	// groupDir is the folder group: locations of Node are relative to.
+	groupDir string
+}
+
+// Refs lists every FileRef in document order. Refs with a malformed location
+// are returned with an empty Path and an error naming them.
+func (w *Workspace) Refs() ([]*Ref, error) {
+	var refs []*Ref
+	var errs []string
+	var walk func(parent *Node, groupDir string, groups []string)
+	walk = func(parent *Node, groupDir string, groups []string) {
+		for _, child := range parent.Children {
+			switch {
+			case child.isElement(ElementFileRef):
+				ref := &Ref{Node: child, Parent: parent, Groups: groups, groupDir: groupDir}
+				loc, err := ParseLocation(child.Attr("location"))
+				if err != nil {
+					errs = append(errs, err.Error())
+				} else {
+					ref.Location = loc
+					ref.Path, _ = loc.Resolve(w.ContainerDir(), groupDir)
+				}
+				refs = append(refs, ref)
+			case child.isElement(ElementGroup):
+				dir := groupDir
+				if loc, err := ParseLocation(child.Attr("location")); err == nil {
+					if resolved, ok := loc.Resolve(w.ContainerDir(), groupDir); ok {
+						dir = resolved
+					}
+				}
+				name := child.Attr("name")
+				if name == "" {
+					name = filepath.Base(dir)
+				}
+				walk(child, dir, append(append([]string(nil), groups...), name))
+			}
+		}
+	}
+	walk(w.root, w.ContainerDir(), nil)
+	if len(errs) > 0 {
+		return refs, fmt.Errorf("xcworkspace: %s", strings.Join(errs, "; "))
+	}
+	return refs, nil
+}
+
+// Find returns the refs resolving to the absolute path target.
+func (w *Workspace) Find(target string) []*Ref {
+	target = filepath.Clean(target)
+	refs, _ := w.Refs()
+	var found []*Ref
+	for _, r := range refs {
+		if r.Path == target {
+			found = append(found, r)
+		}
+	}
+	return found
+}
+
+// Duplicates returns the refs that resolve to a path an earlier ref already
+// points at, however their locations are written.
+func (w *Workspace) Duplicates() []*Ref {
+	refs, _ := w.Refs()
+	seen := map[string]bool{}
+	var dups []*Ref
+	for _, r := range refs {
+		if r.Path == "" {
+			continue
+		}
+		if seen[r.Path] {
+			dups = append(dups, r)
+		}
+		seen[r.Path] = true
+	}
+	return dups
+}
+
+// Add adds a FileRef to the absolute path target inside the groups named by
+// groups, creating them as needed, with a location relative to the group.
+// When a ref already resolves to target anywhere in the workspace, that ref
+// is returned instead and added is false.
+func (w *Workspace) Add(groups []string, target string) (ref *Ref, added bool, err error) {
+	if !filepath.IsAbs(target) {
+		return nil, false, fmt.Errorf("xcworkspace: %s is not an absolute path", target)
+	}
+	if existing := w.Find(target); len(existing) > 0 {
+		return existing[0], false, nil
+	}
+
+	parent, groupDir := w.root, w.ContainerDir()
+	for _, name := range groups {
+		parent, groupDir = w.group(parent, groupDir, name)
+	}
+	loc, err := Relative(target, groupDir)
+	if err != nil {
+		return nil, false, err
+	}
+	node := &Node{Kind: ElementNode, Name: ElementFileRef}
+	node.SetAttr("location", loc.String())
+	parent.Children = append(parent.Children, node)
+	return &Ref{Node: node, Parent: parent, Groups: groups, Location: loc, Path: filepath.Clean(target), groupDir: groupDir}, true, nil
+}
+
+// group returns the child group named name of parent, creating it when
+// missing, and the folder it resolves to.
+func (w *Workspace) group(parent *Node, parentDir, name string) (*Node, string) {
+	for _, child := range parent.Children {
+		if !child.isElement(ElementGroup) || child.Attr("name") != name {
+			continue
+		}
+		if loc, err := ParseLocation(child.Attr("location")); err == nil {
+			if dir, ok := loc.Resolve(w.ContainerDir(), parentDir); ok {
+				return child, dir
+			}
+		}
+		return child, parentDir
+	}
+	// Like Xcode, new groups are plain folders in the navigator rooted at
+	// the container.
+	node := &Node{Kind: ElementNode, Name: ElementGroup}
+	node.SetAttr("location", KindContainer+":")
+	node.SetAttr("name", name)
+	parent.Children = append(parent.Children, node)
+	return node, w.ContainerDir()
+}
+
+// Remove deletes every ref resolving to the absolute path target and
+// returns how many were removed.
+func (w *Workspace) Remove(target string) int {
+	refs := w.Find(target)
+	for _, r := range refs {
+		r.Parent.removeChild(r.Node)
+	}
+	return len(refs)
+}
+
+// RemoveRef deletes one ref.
+func (w *Workspace) RemoveRef(r *Ref) {
+	r.Parent.removeChild(r.Node)
+}
+
+func (n *Node) removeChild(child *Node) {
+	for i, c := range n.Children {
+		if c == child {
+			n.Children = append(n.Children[:i], n.Children[i+1:]...)
+			return
+		}
+	}
+}
+
+// Move places the first ref resolving to target at position index among
+// the FileRef and Group items of its parent; a negative index counts from
+// the end.
+func (w *Workspace) Move(target string, index int) error {
+	refs := w.Find(target)
+	if len(refs) == 0 {
+		return fmt.Errorf("xcworkspace: no reference to %s", target)
+	}
+	r := refs[0]
+
+	var items []int
+	for i, c := range r.Parent.Children {
+		if c.isElement(ElementFileRef) || c.isElement(ElementGroup) {
+			items = append(items, i)
+		}
+	}
+	if index < 0 {
+		index += len(items)
+	}
+	if index < 0 || index >= len(items) {
+		return fmt.Errorf("xcworkspace: position %d out of range (%d items)", index, len(items))
+	}
+
+	r.Parent.removeChild(r.Node)
+	// Positions of the remaining items, then insert before the one now at index.
+	items = items[:0]
+	for i, c := range r.Parent.Children {
+		if c.isElement(ElementFileRef) || c.isElement(ElementGroup) {
+			items = append(items, i)
+		}
+	}
+	at := len(r.Parent.Children)
+	if index < len(items) {
+		at = items[index]
+	}
+	children := append([]*Node(nil), r.Parent.Children[:at]...)
+	children = append(children, r.Node)
+	r.Parent.Children = append(children, r.Parent.Children[at:]...)
+	return nil
+}
+
+// Relativize rewrites an absolute location as a group: location relative to
+// the enclosing group and reports whether it changed.
+func (w *Workspace) Relativize(r *Ref) (bool, error) {
+	if r.Location.Kind != KindAbsolute {
+		return false, nil
+	}
+	loc, err := Relative(r.Path, r.groupDir)
+	if err != nil {
+		return false, err
+	}
+	r.Node.SetAttr("location", loc.String())
+	r.Location = loc
+	return true, nil
+}
+
+// ContentsPath is the contents.xcworkspacedata of the bundle.
+func (w *Workspace) ContentsPath() string {
+	return filepath.Join(w.Path, ContentsFile)
+}
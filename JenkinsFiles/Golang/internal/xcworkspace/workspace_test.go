@@ -0,0 +1,161 @@
+package xcworkspace
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const workspaceXML = `<?xml version="1.0" encoding="UTF-8"?>
+<Workspace
+   version = "1.0">
+   <FileRef
+      location = "absolute:/jenkins/Game/UnityBuild/Unity-iPhone.xcodeproj">
+   </FileRef>
+   <Group
+      location = "group:Cocos"
+      name = "Cocos">
+      <FileRef
+         location = "absolute:/jenkins/Game/Cocos/build/ios/proj/Game.xcodeproj">
+      </FileRef>
+   </Group>
+   <Group
+      location = "container:"
+      name = "Pods">
+      <FileRef
+         location = "absolute:/jenkins/Game/XcodeWorkspace/Pods/Pods.xcodeproj">
+      </FileRef>
+      <FileRef
+         location = "group:Local/Local.xcodeproj">
+      </FileRef>
+   </Group>
+</Workspace>
+`
+
+func TestRelativize(t *testing.T) {
+	w, err := Parse([]byte(workspaceXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Path = "/jenkins/Game/XcodeWorkspace/Game.xcworkspace"
+
+	refs, err := w.Refs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		changed  bool
+		location string
+	}{
+		// Top-level refs are relative to the folder holding the workspace...
+		{true, "group:../UnityBuild/Unity-iPhone.xcodeproj"},
+		// ...and refs in a group to the group's folder, here
+		// XcodeWorkspace/Cocos.
+		{true, "group:../../Cocos/build/ios/proj/Game.xcodeproj"},
+		{true, "group:Pods/Pods.xcodeproj"},
+		{false, "group:Local/Local.xcodeproj"},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("got %d refs, want %d", len(refs), len(want))
+	}
+	for i, r := range refs {
+		path := r.Path
+		changed, err := w.Relativize(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed != want[i].changed || r.Node.Attr("location") != want[i].location {
+			t.Errorf("ref %d: changed %v, location %s; want %v, %s", i, changed, r.Node.Attr("location"), want[i].changed, want[i].location)
+		}
+		// The ref still points at the same project.
+		if resolved, _ := r.Location.Resolve(w.ContainerDir(), r.groupDir); resolved != path {
+			t.Errorf("ref %d resolves to %s after Relativize, want %s", i, resolved, path)
+		}
+	}
+
+	// Saved and read back, the workspace keeps the relative locations.
+	again, err := Parse(w.Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	again.Path = filepath.Join("/elsewhere", "XcodeWorkspace", "Game.xcworkspace")
+	refs, _ = again.Refs()
+	if got, want := refs[0].Path, "/elsewhere/UnityBuild/Unity-iPhone.xcodeproj"; got != want {
+		t.Errorf("after moving the folders, ref 0 = %s, want %s", got, want)
+	}
+}
+
+func TestAddUsesRelativeLocation(t *testing.T) {
+	w := New("/jenkins/Game/XcodeWorkspace/Game.xcworkspace")
+	ref, added, err := w.Add([]string{"Cocos"}, "/jenkins/Game/Cocos/build/ios/proj/Game.xcodeproj")
+	if err != nil || !added {
+		t.Fatalf("Add = %v, %v", added, err)
+	}
+	if got, want := ref.Node.Attr("location"), "group:../Cocos/build/ios/proj/Game.xcodeproj"; got != want {
+		t.Errorf("location = %s, want %s", got, want)
+	}
+	if _, added, _ := w.Add(nil, "/jenkins/Game/Cocos/build/ios/proj/Game.xcodeproj"); added {
+		t.Error("Add added a second ref to the same project")
+	}
+	if _, _, err := w.Add(nil, "relative/Game.xcodeproj"); err == nil {
+		t.Error("Add accepted a relative path")
+	}
+}
+
+func TestRoundTripKeepsXcodeFormatting(t *testing.T) {
+	golden := filepath.Join("testdata", "Nested.xcworkspace", ContentsFile)
+	data, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := Load(filepath.Dir(golden))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Comments, unknown elements and text come back byte for byte.
+	if got := w.Marshal(); !bytes.Equal(got, data) {
+		t.Fatalf("round trip changed the file:\n%s", got)
+	}
+
+	w.Path = "/jenkins/Game/XcodeWorkspace/Game.xcworkspace"
+	refs, err := w.Refs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		path   string
+		groups []string
+	}{
+		{"/jenkins/Game/UnityBuild/Unity-iPhone.xcodeproj", nil},
+		{"/jenkins/Game/XcodeWorkspace/Game/Cocos/build/ios/proj/Game.xcodeproj", []string{"Game", "Cocos"}},
+		{"/jenkins/Game/XcodeWorkspace/Tools/Exporter.xcodeproj", []string{"Game", "Cocos", "Tools"}},
+		{"/jenkins/Game/Shared/Shared.xcodeproj", []string{"Game"}},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("got %d refs, want %d", len(refs), len(want))
+	}
+	for i, r := range refs {
+		if r.Path != want[i].path || !reflect.DeepEqual(r.Groups, want[i].groups) {
+			t.Errorf("ref %d = %s in %v, want %s in %v", i, r.Path, r.Groups, want[i].path, want[i].groups)
+		}
+	}
+
+	if n := w.Remove(want[1].path); n != 1 {
+		t.Errorf("Remove removed %d refs, want 1", n)
+	}
+	if err := w.Move(want[3].path, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Move(want[3].path, 2); err == nil {
+		t.Error("Move accepted a position past the last item")
+	}
+	edited, err := os.ReadFile(filepath.Join("testdata", "edited.xcworkspacedata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Marshal(); !bytes.Equal(got, edited) {
+		t.Errorf("after Remove and Move:\n%s", got)
+	}
+}
@@ -0,0 +1,284 @@
+// Command jenkinstool bundles the build-pipeline helpers for Unity and
+// Cocos iOS projects into one binary:
+//
+//	jenkinstool cocos build             build the Cocos project, then wire it into the workspace
+//	jenkinstool cocos build-config      generate buildConfig_ios.json for the Unity app
+//	jenkinstool cocos parse-log         summarize a Creator build log
+//	jenkinstool cocos patch-xcode       embed UnityFramework in the Cocos Xcode project
+//	jenkinstool unity patch-xcode       patch the Unity Xcode project for use as a framework
+//	jenkinstool icons sync              merge the Unity app icons into the Cocos ones
+//	jenkinstool icons validate          check app icon sets against their Contents.json
+//	jenkinstool icons regenerate        generate a complete app icon set from one image
+//	jenkinstool assets sync             copy the configured asset catalog sets from Unity to Cocos
+//	jenkinstool assets validate         check asset catalog sets against their Contents.json
+//	jenkinstool workspace add           add the Cocos Xcode project to the workspace
+//	jenkinstool workspace normalize     make the workspace locations relative
+//	jenkinstool xcode validate|diff|apply-recipe|settings
+//	jenkinstool backup list|rollback
+//
+// Build it with the version stamped in:
+//
+//	go build -ldflags "-X main.version=$(git describe --tags --always)" -o jenkinstool .
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/changeset"
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/config"
+	"github.com/MeherJOffice/JenkinsSupport/JenkinsFiles/Golang/internal/pbxproj"
+)
+
+// version is set at build time with -ldflags "-X main.version=...".
+var version = "dev"
+
+const toolName = "jenkinstool"
+
+// Exit codes shared by every command.
+const (
+	exitOK = 0
+	// exitFailed: the command could not do its job (missing file, I/O, ...).
+	exitFailed = 1
+	// exitUsage: bad command line.
+	exitUsage = 2
+	// exitFindings: a check ran and found problems (validation errors,
+	// projects that differ).
+	exitFindings = 3
+	// exitWarnings: a check found only warnings.
+	exitWarnings = 4
+)
+
+// command is one "group name" subcommand.
+type command struct {
+	group, name string
+	summary     string
+	run         func(args []string) error
+}
+
+var commands []*command
+
+func register(c *command) {
+	commands = append(commands, c)
+}
+
+// usageError makes main print the command's usage and exit with exitUsage.
+type usageError struct{ msg string }
+
+func (e usageError) Error() string { return e.msg }
+
+func usageErrorf(format string, args ...interface{}) error {
+	return usageError{fmt.Sprintf(format, args...)}
+}
+
+// exitCode ends the program with code without printing anything more; the
+// command already reported its findings.
+type exitCode int
+
+func (c exitCode) Error() string { return fmt.Sprintf("exit status %d", int(c)) }
+
+func main() {
+	sort.Slice(commands, func(i, j int) bool {
+		if commands[i].group != commands[j].group {
+			return commands[i].group < commands[j].group
+		}
+		return commands[i].name < commands[j].name
+	})
+
+	args := os.Args[1:]
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		printHelp()
+		if len(args) == 0 {
+			os.Exit(exitUsage)
+		}
+		return
+	}
+	if args[0] == "version" || args[0] == "-version" || args[0] == "--version" {
+		fmt.Println(toolName, version)
+		return
+	}
+
+	cmd := lookup(args)
+	if cmd == nil {
+		name := args
+		if len(name) > 2 {
+			name = name[:2]
+		}
+		fmt.Fprintf(os.Stderr, "❌ Unknown command %q\n\n", strings.Join(name, " "))
+		printHelp()
+		os.Exit(exitUsage)
+	}
+	os.Exit(runCommand(cmd, args[2:]))
+}
+
+func lookup(args []string) *command {
+	if len(args) < 2 {
+		return nil
+	}
+	for _, c := range commands {
+		if c.group == args[0] && c.name == args[1] {
+			return c
+		}
+	}
+	return nil
+}
+
+func runCommand(cmd *command, args []string) int {
+	err := cmd.run(args)
+	var usage usageError
+	var code exitCode
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &code):
+		return int(code)
+	case errors.As(err, &usage):
+		fmt.Fprintln(os.Stderr, "❌", usage.msg)
+		fmt.Fprintf(os.Stderr, "Run '%s %s %s -h' for usage.\n", toolName, cmd.group, cmd.name)
+		return exitUsage
+	}
+	fmt.Println("❌", err)
+	return exitFailed
+}
+
+func printHelp() {
+	fmt.Fprintf(os.Stderr, "usage: %s <group> <command> [flags] [args]\n\ncommands:\n", toolName)
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-24s %s\n", c.group+" "+c.name, c.summary)
+	}
+	fmt.Fprintf(os.Stderr, "  %-24s %s\n", "version", "print the tool version")
+	fmt.Fprintf(os.Stderr, `
+exit codes:
+  %d  success
+  %d  the command failed
+  %d  invalid command line
+  %d  a check found problems (validation errors, differences)
+  %d  a check found only warnings
+`, exitOK, exitFailed, exitUsage, exitFindings, exitWarnings)
+}
+
+// newFlagSet returns the flag set of cmd. Parse errors are returned rather
+// than exiting, so main can map them to exitUsage.
+func newFlagSet(cmd *command, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.group+" "+cmd.name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s %s %s [flags] %s\n%s.\n\nflags:\n", toolName, cmd.group, cmd.name, args, cmd.summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args, turning bad flags into a usageError.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError{err.Error()}
+	}
+	return nil
+}
+
+// planFlags are the -dry-run flag and the path settings shared by the
+// commands that change files.
+type planFlags struct {
+	dryRun *bool
+	paths  *config.Flags
+}
+
+func bindPlanFlags(fs *flag.FlagSet) planFlags {
+	return planFlags{
+		dryRun: fs.Bool("dry-run", false, "print the change plan without writing anything"),
+		paths:  config.Bind(fs),
+	}
+}
+
+// open resolves the paths over defaults and starts the change plan of cmd.
+func (f planFlags) open(cmd *command, defaults config.Config) (*config.Config, *changeset.Plan, error) {
+	cfg, err := f.paths.Resolve(defaults)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	plan, err := openPlan(cmd, *f.dryRun)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, plan, nil
+}
+
+// openPlan starts the change plan of cmd, journaled under the command name.
+func openPlan(cmd *command, dryRun bool) (*changeset.Plan, error) {
+	return changeset.Open(cmd.group+"-"+cmd.name, dryRun)
+}
+
+// executableDir is the default base dir of the commands that historically
+// ran next to the binary.
+func executableDir() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return "."
+	}
+	return filepath.Dir(exe)
+}
+
+// logToFile sends the log package to path, for the patchers whose detailed
+// log Jenkins archives. The returned func restores stderr and closes it.
+func logToFile(path string) (func(), error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log file: %w", err)
+	}
+	log.SetOutput(f)
+	return func() {
+		log.SetOutput(os.Stderr)
+		f.Close()
+	}, nil
+}
+
+// discoverXcodeProj picks the .xcodeproj in dir the way
+// config.DiscoverXcodeProj does and reports the choice to w, stdout or, for
+// the patchers, the log.
+func discoverXcodeProj(w io.Writer, what, dir, name, product string) (string, error) {
+	d, err := config.DiscoverXcodeProj(dir, name, product)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", what, err)
+	}
+	reportDiscovery(w, what, d)
+	return d.Path, nil
+}
+
+// reportDiscovery says which project or workspace was picked and why.
+func reportDiscovery(w io.Writer, what string, d *config.Discovery) {
+	fmt.Fprintf(w, "📂 %s: %s\n", what, d.Path)
+	if d.Mismatch {
+		fmt.Fprintf(w, "⚠️ %s: %s\n", what, d.Reason)
+	} else {
+		fmt.Fprintf(w, "ℹ️ %s: %s\n", what, d.Reason)
+	}
+}
+
+// loadPbxproj loads a project.pbxproj, given it or its .xcodeproj.
+func loadPbxproj(path string) (*pbxproj.Project, error) {
+	project, err := pbxproj.Load(pbxprojPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pbxproj: %w", err)
+	}
+	return project, nil
+}
+
+// pbxprojPath accepts a project.pbxproj or its .xcodeproj.
+func pbxprojPath(path string) string {
+	if strings.HasSuffix(strings.TrimSuffix(path, "/"), ".xcodeproj") {
+		return config.PbxprojPath(path)
+	}
+	return path
+}